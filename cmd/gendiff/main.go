@@ -2,9 +2,21 @@ package main
 
 import (
 	"code"
+	"code/compare"
+	"code/diff"
+	"code/formatter"
+	"code/matrix"
+	"code/merge"
+	"code/parser"
+	"code/redact"
+	"code/resolve"
+	"code/schema"
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
 
 	"github.com/urfave/cli/v3"
 )
@@ -21,24 +33,213 @@ func main() {
 				DefaultText: "\"stylish\"",
 				Usage:       "output format",
 			},
+			&cli.StringFlag{
+				Name:  "template",
+				Usage: "text/template file for the template format",
+			},
+			&cli.IntFlag{
+				Name:  "context",
+				Usage: "show only this many unchanged keys around changes in stylish output",
+			},
+			&cli.BoolFlag{
+				Name:  "quote-strings",
+				Usage: "quote empty, padded and number-, boolean- or null-like strings in stylish output",
+			},
+			&cli.IntFlag{
+				Name:  "word-diff",
+				Usage: "show changes to single-line strings of at least this many characters as word diffs",
+			},
+			&cli.BoolFlag{
+				Name:  "type-changes",
+				Usage: "report values that changed type separately from regular changes",
+			},
+			&cli.BoolFlag{
+				Name:  "detect-moves",
+				Usage: "report subtrees that moved to another path as moved or renamed",
+			},
+			&cli.Float64Flag{
+				Name:  "move-threshold",
+				Value: 1,
+				Usage: "minimum similarity (0-1] for move detection",
+			},
+			&cli.Float64Flag{
+				Name:  "abs-tolerance",
+				Usage: "treat numbers as equal when they differ by at most this amount",
+			},
+			&cli.Float64Flag{
+				Name:  "rel-tolerance",
+				Usage: "treat numbers as equal when they differ by at most this fraction",
+			},
+			&cli.BoolFlag{
+				Name:  "numeric-strings",
+				Usage: "compare strings holding numbers as numbers (\"8080\" equals 8080)",
+			},
+			&cli.BoolFlag{
+				Name:  "ignore-case",
+				Usage: "compare strings case-insensitively",
+			},
+			&cli.BoolFlag{
+				Name:  "normalize-whitespace",
+				Usage: "ignore leading, trailing and repeated whitespace in strings",
+			},
+			&cli.StringSliceFlag{
+				Name:  "semantic",
+				Usage: "treat equivalent values as equal: duration, size, semver, timestamp, ip or all",
+			},
+			&cli.StringSliceFlag{
+				Name:  "compare",
+				Usage: "compare paths matching a pattern with their own options, e.g. \"**.price=abs-tolerance:0.01;numeric-strings\" (repeatable)",
+			},
+			&cli.BoolFlag{
+				Name:  "redact",
+				Usage: "hide secret values (passwords, tokens, keys, PEM blocks, JWTs) behind hash markers",
+			},
+			&cli.StringSliceFlag{
+				Name:  "redact-key",
+				Usage: "also redact values under keys matching this pattern, e.g. \"*_pin\" (implies --redact)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "redact-value",
+				Usage: "also redact string parts matching this regular expression (implies --redact)",
+			},
+			&cli.StringFlag{
+				Name:    "redact-hmac-key",
+				Usage:   "key for redaction markers, to compare them across runs; random per run by default (implies --redact)",
+				Sources: cli.EnvVars("GENDIFF_REDACT_KEY"),
+			},
+			&cli.BoolFlag{
+				Name:  "lenient-json",
+				Usage: "accept comments, trailing commas, unquoted keys and single quotes in .json files",
+			},
+			&cli.BoolFlag{
+				Name:  "strict",
+				Usage: "warn about duplicate keys and non-string YAML keys",
+			},
+			&cli.BoolFlag{
+				Name:  "yaml-anchors",
+				Usage: "compare YAML aliases and merge keys as written instead of expanding them",
+			},
+			&cli.BoolFlag{
+				Name:  "yaml-tags",
+				Usage: "resolve YAML !include, !env and !secret tags",
+			},
+			&cli.BoolFlag{
+				Name:  "resolve",
+				Usage: "expand ${VAR} placeholders and resolve $ref and YAML !include before comparing",
+			},
+			&cli.StringFlag{
+				Name:  "env-file",
+				Usage: "read ${VAR} values from this file before the environment (implies --resolve)",
+			},
+			&cli.BoolFlag{
+				Name:  "hocon-conf",
+				Usage: "parse .conf files as HOCON",
+			},
+			&cli.StringFlag{
+				Name:  "csv-key",
+				Usage: "column whose values identify CSV and TSV rows (default: the first column)",
+			},
+			&cli.StringFlag{
+				Name:  "xml-namespaces",
+				Value: "strip",
+				Usage: "how to key namespaced XML names: strip, prefix or uri",
+			},
+			&cli.StringSliceFlag{
+				Name:  "xml-array",
+				Usage: "XML element name to always read as an array, even when it occurs once (repeatable)",
+			},
+			&cli.StringFlag{
+				Name:  "schema",
+				Usage: "JSON Schema to validate both files against; its defaults are applied before comparison",
+			},
+			&cli.StringSliceFlag{
+				Name:  "left",
+				Usage: "compare these files, merged in order, instead of the first argument (repeatable)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "right",
+				Usage: "compare with these files, merged in order, instead of the second argument (repeatable)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "list-merge",
+				Usage: "how --left and --right layers combine lists: replace, append, index or key:field, optionally for a path, e.g. \"spec.containers=key:name\"",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "write the diff to a file instead of stdout",
+			},
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
-			args := c.Args()
-			if args.Len() < 2 {
-				return fmt.Errorf("usage: gendiff <filepath1> <filepath2>")
+			left, right, err := sides(c)
+			if err != nil {
+				return err
+			}
+			format, err := outputFormat(c)
+			if err != nil {
+				return err
 			}
 
-			filePath1 := args.Get(0)
-			filePath2 := args.Get(1)
-			format := c.String("format")
-
-			res, err := code.GenDiff(filePath1, filePath2, format)
+			opts, err := differOptions(c)
 			if err != nil {
 				return err
 			}
+			if format == formatter.FormatTemplate {
+				opts = append(opts, printWarnings)
+			}
+			differ := code.NewDiffer(opts...)
+
+			return writeOutput(c, func(w io.Writer) error {
+				return writeDiff(ctx, w, differ, left, right, format)
+			})
+		},
+		Commands: []*cli.Command{
+			{
+				Name:      "matrix",
+				Usage:     "compare several files at once, e.g. one per environment",
+				ArgsUsage: "<filepath1> <filepath2> [filepath...]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "format",
+						Aliases: []string{"f"},
+						Value:   matrix.FormatTable,
+						Usage:   "matrix output format: table or json",
+					},
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "include keys whose value is the same everywhere",
+					},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.Args().Len() < 2 {
+						return fmt.Errorf("usage: gendiff matrix <filepath1> <filepath2> [filepath...]")
+					}
+					if c.IsSet("list-merge") {
+						return fmt.Errorf("usage: --list-merge needs several --left or --right files")
+					}
 
-			fmt.Println(res)
-			return nil
+					opts, err := differOptions(c)
+					if err != nil {
+						return err
+					}
+
+					m, err := code.NewDiffer(append(opts, printWarnings)...).Matrix(ctx, c.Args().Slice()...)
+					if err != nil {
+						return err
+					}
+					if !c.Bool("all") {
+						m = m.Divergent()
+					}
+
+					return writeOutput(c, func(w io.Writer) error {
+						if err := matrix.Write(w, m, c.String("format")); err != nil {
+							return err
+						}
+						_, err := fmt.Fprintln(w)
+						return err
+					})
+				},
+			},
 		},
 	}
 
@@ -47,3 +248,283 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+var xmlNamespaceModes = map[string]parser.XMLNamespaces{
+	"strip":  parser.XMLNamespacesStrip,
+	"prefix": parser.XMLNamespacesPrefix,
+	"uri":    parser.XMLNamespacesURI,
+}
+
+// outputFormat returns the --format value; --template alone selects the
+// template format and cannot be combined with another one.
+func outputFormat(c *cli.Command) (string, error) {
+	format := c.String("format")
+	if c.String("template") == "" {
+		return format, nil
+	}
+	if !c.IsSet("format") {
+		return formatter.FormatTemplate, nil
+	}
+	if format != formatter.FormatTemplate {
+		return "", fmt.Errorf("usage: --template requires --format %s, got %q", formatter.FormatTemplate, format)
+	}
+	return format, nil
+}
+
+// sides returns the files to merge for each side: the --left and --right
+// layers, or the two arguments. --list-merge only applies to layers.
+func sides(c *cli.Command) ([]string, []string, error) {
+	left, right := c.StringSlice("left"), c.StringSlice("right")
+	if len(left) == 0 && len(right) == 0 {
+		args := c.Args()
+		if args.Len() < 2 {
+			return nil, nil, fmt.Errorf("usage: gendiff <filepath1> <filepath2>")
+		}
+		left, right = []string{args.Get(0)}, []string{args.Get(1)}
+	} else if len(left) == 0 || len(right) == 0 || c.Args().Len() > 0 {
+		return nil, nil, fmt.Errorf("usage: gendiff --left <file>... --right <file>...")
+	}
+
+	if c.IsSet("list-merge") && len(left) == 1 && len(right) == 1 {
+		return nil, nil, fmt.Errorf("usage: --list-merge needs several --left or --right files")
+	}
+	return left, right, nil
+}
+
+// differOptions builds Differ options from the global flags.
+func differOptions(c *cli.Command) ([]code.Option, error) {
+	opts := []code.Option{
+		code.WithFormatterOptions(formatter.WithTemplatePath(c.String("template"))),
+	}
+	if c.IsSet("context") {
+		opts = append(opts, code.WithFormatterOptions(formatter.WithContext(int(c.Int("context")))))
+	}
+	if c.Bool("quote-strings") {
+		opts = append(opts, code.WithFormatterOptions(formatter.WithQuotedStrings()))
+	}
+	if n := c.Int("word-diff"); n > 0 {
+		opts = append(opts, code.WithFormatterOptions(formatter.WithWordDiff(int(n))))
+	}
+	if c.Bool("type-changes") {
+		opts = append(opts, code.WithTypeChanges())
+	}
+	if c.Bool("lenient-json") {
+		opts = append(opts, code.WithParser(&parser.JSONParser{Lenient: true}, ".json"))
+	}
+	resolving := c.Bool("resolve") || c.String("env-file") != ""
+	if c.Bool("yaml-anchors") || c.Bool("yaml-tags") || resolving {
+		p := &parser.YAMLParser{KeepAnchors: c.Bool("yaml-anchors"), Tags: map[string]parser.YAMLTagResolver{}}
+		if c.Bool("yaml-tags") {
+			p.Tags = parser.DefaultYAMLTags()
+		}
+		if resolving {
+			p.Tags["!include"] = parser.YAMLInclude
+		}
+		opts = append(opts, code.WithParser(p, ".yaml", ".yml"))
+	}
+	if resolving {
+		lookup := resolve.LookupFunc(os.LookupEnv)
+		if envFile := c.String("env-file"); envFile != "" {
+			vars, err := resolve.LoadEnvFile(envFile)
+			if err != nil {
+				return nil, err
+			}
+			lookup = resolve.Vars(vars)
+		}
+		opts = append(opts, code.WithEnvExpansion(lookup), code.WithRefResolution())
+		opts = append(opts, code.WithParser(&parser.HOCONParser{LookupEnv: lookup}, hoconExts(c)...))
+	} else if c.Bool("hocon-conf") {
+		opts = append(opts, code.WithParser(&parser.HOCONParser{}, hoconExts(c)...))
+	}
+	if key := c.String("csv-key"); key != "" {
+		opts = append(opts,
+			code.WithParser(&parser.CSVParser{Key: key}, ".csv"),
+			code.WithParser(&parser.CSVParser{Key: key, Comma: '\t'}, ".tsv"),
+		)
+	}
+	if c.IsSet("xml-namespaces") || c.IsSet("xml-array") {
+		mode, ok := xmlNamespaceModes[c.String("xml-namespaces")]
+		if !ok {
+			return nil, fmt.Errorf("unknown --xml-namespaces mode %q", c.String("xml-namespaces"))
+		}
+		opts = append(opts, code.WithParser(&parser.XMLParser{Namespaces: mode, Arrays: c.StringSlice("xml-array")}, ".xml"))
+	}
+	cmpRules, err := comparisonRules(c)
+	if err != nil {
+		return nil, err
+	}
+	if len(cmpRules) > 0 {
+		opts = append(opts, code.WithComparison(cmpRules...))
+	}
+	if c.Bool("detect-moves") || c.IsSet("move-threshold") {
+		opts = append(opts, code.WithMoveDetection(c.Float64("move-threshold")))
+	}
+
+	rules, err := redactionRules(c)
+	if err != nil {
+		return nil, err
+	}
+	if rules != nil {
+		opts = append(opts, code.WithRedaction(rules...))
+		if key := c.String("redact-hmac-key"); key != "" {
+			opts = append(opts, code.WithRedactionKey([]byte(key)))
+		}
+	}
+
+	if schemaPath := c.String("schema"); schemaPath != "" {
+		s, err := schema.Load(schemaPath)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, code.WithSchema(s))
+	}
+	if c.Bool("strict") {
+		opts = append(opts, code.WithStrict())
+	}
+	if specs := c.StringSlice("list-merge"); len(specs) > 0 {
+		rules := make([]merge.Rule, 0, len(specs))
+		for _, spec := range specs {
+			rule, err := merge.ParseRule(spec)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, rule)
+		}
+		opts = append(opts, code.WithListMerge(rules...))
+	}
+
+	return opts, nil
+}
+
+// printWarnings sends warnings to stderr, for outputs that do not list them.
+var printWarnings = code.WithWarningHandler(func(w diff.Warning) {
+	fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+})
+
+// writeOutput runs write against stdout or the file named by --output.
+func writeOutput(c *cli.Command, write func(w io.Writer) error) error {
+	outputPath := c.String("output")
+	if outputPath == "" {
+		return write(os.Stdout)
+	}
+
+	// Write next to the target and rename on success, so that a failed diff
+	// leaves an existing file untouched.
+	out, err := os.CreateTemp(filepath.Dir(outputPath), "."+filepath.Base(outputPath)+".*")
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+	defer os.Remove(out.Name())
+
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(outputPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := out.Chmod(mode); err != nil {
+		out.Close()
+		return fmt.Errorf("create output file: %w", err)
+	}
+
+	if err := write(out); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("write output file: %w", err)
+	}
+	if err := os.Rename(out.Name(), outputPath); err != nil {
+		return fmt.Errorf("write output file: %w", err)
+	}
+	return nil
+}
+
+// comparisonRules builds comparison rules from flags: the global options,
+// then one rule per --compare flag, so that path rules take precedence.
+func comparisonRules(c *cli.Command) ([]compare.Rule, error) {
+	var rules []compare.Rule
+	cmpOpts, err := comparisonOptions(c)
+	if err != nil {
+		return nil, err
+	}
+	if cmpOpts != nil {
+		rules = append(rules, compare.Rule{Options: *cmpOpts})
+	}
+
+	for _, spec := range c.StringSlice("compare") {
+		rule, err := compare.ParseRule(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// comparisonOptions builds comparison options from flags, or nil when none is set.
+func comparisonOptions(c *cli.Command) (*compare.Options, error) {
+	var semantics []compare.Semantic
+	for _, name := range c.StringSlice("semantic") {
+		if name == "all" {
+			semantics = compare.Semantics()
+			continue
+		}
+		s, ok := compare.SemanticByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown semantic comparator %q", name)
+		}
+		semantics = append(semantics, s)
+	}
+
+	opts := compare.Options{
+		AbsTolerance:        c.Float64("abs-tolerance"),
+		RelTolerance:        c.Float64("rel-tolerance"),
+		NumericStrings:      c.Bool("numeric-strings"),
+		IgnoreCase:          c.Bool("ignore-case"),
+		NormalizeWhitespace: c.Bool("normalize-whitespace"),
+		Semantics:           semantics,
+	}
+
+	if opts.AbsTolerance == 0 && opts.RelTolerance == 0 && !opts.NumericStrings &&
+		!opts.IgnoreCase && !opts.NormalizeWhitespace && len(opts.Semantics) == 0 {
+		return nil, nil
+	}
+	return &opts, nil
+}
+
+// hoconExts returns the extensions read as HOCON.
+func hoconExts(c *cli.Command) []string {
+	if c.Bool("hocon-conf") {
+		return []string{".hocon", ".conf"}
+	}
+	return []string{".hocon"}
+}
+
+// redactionRules builds redaction rules from flags, or nil when redaction is off.
+func redactionRules(c *cli.Command) ([]redact.Rule, error) {
+	keys, values := c.StringSlice("redact-key"), c.StringSlice("redact-value")
+	if !c.Bool("redact") && len(keys) == 0 && len(values) == 0 && c.String("redact-hmac-key") == "" {
+		return nil, nil
+	}
+
+	rules := redact.Defaults()
+	for _, key := range keys {
+		rules = append(rules, redact.Rule{Key: key})
+	}
+	for _, expr := range values {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --redact-value: %w", err)
+		}
+		rules = append(rules, redact.Rule{Value: re})
+	}
+	return rules, nil
+}
+
+func writeDiff(ctx context.Context, w io.Writer, differ *code.Differ, left, right []string, format string) error {
+	if err := differ.WriteLayeredDiff(ctx, w, left, right, format); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintln(w)
+	return err
+}
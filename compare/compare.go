@@ -0,0 +1,230 @@
+// Package compare decides whether two parsed values are equal, optionally
+// tolerating representation differences that do not change their meaning.
+package compare
+
+import (
+	"code/internal/utils"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Options relaxes value equality. The zero value means exact equality.
+type Options struct {
+	// AbsTolerance treats numbers as equal when |a-b| <= AbsTolerance.
+	AbsTolerance float64
+	// RelTolerance treats numbers as equal when |a-b| <= RelTolerance*max(|a|, |b|).
+	RelTolerance float64
+	// NumericStrings compares strings that hold numbers ("8080") as numbers.
+	NumericStrings bool
+	// IgnoreCase compares strings case-insensitively.
+	IgnoreCase bool
+	// NormalizeWhitespace trims strings and collapses inner whitespace runs.
+	NormalizeWhitespace bool
+	// Semantics are tried in order before the other options; the first one
+	// that recognizes both values decides equality.
+	Semantics []Semantic
+}
+
+// Rule applies Options to values whose dotted path matches Pattern.
+// Pattern segments use path.Match syntax and "**" matches any number of
+// segments, e.g. "**.port" or "limits.*". An empty pattern matches everything.
+type Rule struct {
+	Pattern string
+	Options Options
+}
+
+var ErrInvalidRule = errors.New("invalid comparison rule")
+
+// ParseRule reads a rule written as "pattern=option;option", e.g.
+// "**.price=abs-tolerance:0.01;numeric-strings". Options are abs-tolerance:N,
+// rel-tolerance:N, numeric-strings, ignore-case, normalize-whitespace and
+// semantic:NAME, which may be repeated and accepts "all".
+func ParseRule(s string) (Rule, error) {
+	pattern, options, ok := strings.Cut(s, "=")
+	pattern = strings.TrimSpace(pattern)
+	if !ok || pattern == "" {
+		return Rule{}, fmt.Errorf("%w: %q: expected pattern=options", ErrInvalidRule, s)
+	}
+
+	rule := Rule{Pattern: pattern}
+	for _, option := range strings.Split(options, ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(option), ":")
+		if err := rule.Options.set(name, strings.TrimSpace(value)); err != nil {
+			return Rule{}, fmt.Errorf("%w: %q: %v", ErrInvalidRule, s, err)
+		}
+	}
+	return rule, nil
+}
+
+func (o *Options) set(name, value string) error {
+	switch name {
+	case "abs-tolerance", "rel-tolerance":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || f < 0 {
+			return fmt.Errorf("%s needs a non-negative number", name)
+		}
+		if name == "abs-tolerance" {
+			o.AbsTolerance = f
+		} else {
+			o.RelTolerance = f
+		}
+	case "numeric-strings":
+		o.NumericStrings = true
+	case "ignore-case":
+		o.IgnoreCase = true
+	case "normalize-whitespace":
+		o.NormalizeWhitespace = true
+	case "semantic":
+		if value == "all" {
+			o.Semantics = append(o.Semantics, Semantics()...)
+			return nil
+		}
+		s, ok := SemanticByName(value)
+		if !ok {
+			return fmt.Errorf("unknown semantic comparator %q", value)
+		}
+		o.Semantics = append(o.Semantics, s)
+	default:
+		return fmt.Errorf("unknown option %q", name)
+	}
+	return nil
+}
+
+// Comparer compares values using the options of the last matching rule.
+type Comparer struct {
+	rules []Rule
+}
+
+func New(rules ...Rule) *Comparer {
+	return &Comparer{rules: rules}
+}
+
+// Equal reports whether a and b at keyPath are equal.
+func (c *Comparer) Equal(keyPath string, a, b interface{}) bool {
+	equal, _ := c.Compare(keyPath, a, b)
+	return equal
+}
+
+// Compare reports whether a and b at keyPath are equal. For scalars matched
+// by a semantic comparator it also returns a note describing the change.
+func (c *Comparer) Compare(keyPath string, a, b interface{}) (bool, string) {
+	if reflect.DeepEqual(a, b) {
+		return true, ""
+	}
+
+	switch va := a.(type) {
+	case map[string]interface{}:
+		vb, ok := b.(map[string]interface{})
+		if !ok || len(va) != len(vb) {
+			return false, ""
+		}
+		for k, v := range va {
+			other, ok := vb[k]
+			if !ok || !c.Equal(utils.JoinPath(keyPath, k), v, other) {
+				return false, ""
+			}
+		}
+		return true, ""
+
+	case []interface{}:
+		vb, ok := b.([]interface{})
+		if !ok || len(va) != len(vb) {
+			return false, ""
+		}
+		for i := range va {
+			if !c.Equal(keyPath, va[i], vb[i]) {
+				return false, ""
+			}
+		}
+		return true, ""
+	}
+
+	opts, ok := c.optionsFor(keyPath)
+	if !ok {
+		return false, ""
+	}
+	return opts.compareScalars(a, b)
+}
+
+func (c *Comparer) optionsFor(keyPath string) (Options, bool) {
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if utils.MatchPath(c.rules[i].Pattern, keyPath) {
+			return c.rules[i].Options, true
+		}
+	}
+	return Options{}, false
+}
+
+func (o Options) compareScalars(a, b interface{}) (bool, string) {
+	for _, s := range o.Semantics {
+		if equal, note, ok := s.Compare(a, b); ok {
+			return equal, note
+		}
+	}
+
+	if na, nb, ok := o.numbers(a, b); ok {
+		return o.equalNumbers(na, nb), ""
+	}
+
+	sa, okA := a.(string)
+	sb, okB := b.(string)
+	if okA && okB {
+		return o.equalStrings(sa, sb), ""
+	}
+
+	return false, ""
+}
+
+// numbers returns both values as numbers when they are numbers, or numeric
+// strings with NumericStrings enabled.
+func (o Options) numbers(a, b interface{}) (float64, float64, bool) {
+	na, okA := o.toNumber(a)
+	nb, okB := o.toNumber(b)
+	return na, nb, okA && okB
+}
+
+func (o Options) toNumber(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case int:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	case string:
+		if !o.NumericStrings {
+			return 0, false
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func (o Options) equalNumbers(a, b float64) bool {
+	if a == b {
+		return true
+	}
+
+	delta := math.Abs(a - b)
+	if delta <= o.AbsTolerance {
+		return true
+	}
+	return delta <= o.RelTolerance*math.Max(math.Abs(a), math.Abs(b))
+}
+
+func (o Options) equalStrings(a, b string) bool {
+	if o.NormalizeWhitespace {
+		a = strings.Join(strings.Fields(a), " ")
+		b = strings.Join(strings.Fields(b), " ")
+	}
+	if o.IgnoreCase {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
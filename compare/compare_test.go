@@ -0,0 +1,154 @@
+package compare
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComparer_Equal(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []Rule
+		path     string
+		a, b     interface{}
+		expected bool
+	}{
+		{name: "exact without rules", a: 0.30000000000000004, b: 0.3, expected: false},
+		{name: "identical without rules", a: "x", b: "x", expected: true},
+		{name: "absolute tolerance", rules: []Rule{{Options: Options{AbsTolerance: 1e-9}}}, a: 0.30000000000000004, b: 0.3, expected: true},
+		{name: "absolute tolerance exceeded", rules: []Rule{{Options: Options{AbsTolerance: 0.01}}}, a: 1.0, b: 1.1, expected: false},
+		{name: "relative tolerance", rules: []Rule{{Options: Options{RelTolerance: 0.01}}}, a: 1000.0, b: 1005.0, expected: true},
+		{name: "relative tolerance exceeded", rules: []Rule{{Options: Options{RelTolerance: 0.01}}}, a: 1.0, b: 1.05, expected: false},
+		{name: "numeric string", rules: []Rule{{Options: Options{NumericStrings: true}}}, a: "8080", b: 8080.0, expected: true},
+		{name: "numeric strings both sides", rules: []Rule{{Options: Options{NumericStrings: true}}}, a: "1.50", b: " 1.5", expected: true},
+		{name: "numeric string disabled", rules: []Rule{{Options: Options{IgnoreCase: true}}}, a: "8080", b: 8080.0, expected: false},
+		{name: "non numeric string", rules: []Rule{{Options: Options{NumericStrings: true}}}, a: "80a", b: 80.0, expected: false},
+		{name: "ignore case", rules: []Rule{{Options: Options{IgnoreCase: true}}}, a: "INFO", b: "info", expected: true},
+		{name: "normalize whitespace", rules: []Rule{{Options: Options{NormalizeWhitespace: true}}}, a: " a  b\n", b: "a b", expected: true},
+		{name: "case still matters", rules: []Rule{{Options: Options{NormalizeWhitespace: true}}}, a: "A", b: "a", expected: false},
+		{name: "different kinds", rules: []Rule{{Options: Options{AbsTolerance: 1}}}, a: true, b: 1.0, expected: false},
+		{
+			name:     "rule for other path",
+			rules:    []Rule{{Pattern: "**.port", Options: Options{NumericStrings: true}}},
+			path:     "server.host",
+			a:        "80",
+			b:        80.0,
+			expected: false,
+		},
+		{
+			name:     "rule for matching path",
+			rules:    []Rule{{Pattern: "**.port", Options: Options{NumericStrings: true}}},
+			path:     "server.port",
+			a:        "80",
+			b:        80.0,
+			expected: true,
+		},
+		{
+			name: "last matching rule wins",
+			rules: []Rule{
+				{Options: Options{IgnoreCase: true}},
+				{Pattern: "strict.*", Options: Options{}},
+			},
+			path:     "strict.level",
+			a:        "INFO",
+			b:        "info",
+			expected: false,
+		},
+		{
+			name:     "arrays element wise",
+			rules:    []Rule{{Options: Options{AbsTolerance: 0.5}}},
+			a:        []interface{}{1.0, 2.0},
+			b:        []interface{}{1.2, 2.1},
+			expected: true,
+		},
+		{
+			name:     "arrays of different length",
+			rules:    []Rule{{Options: Options{AbsTolerance: 0.5}}},
+			a:        []interface{}{1.0},
+			b:        []interface{}{1.0, 2.0},
+			expected: false,
+		},
+		{
+			name:     "objects use nested paths",
+			rules:    []Rule{{Pattern: "list.port", Options: Options{NumericStrings: true}}},
+			path:     "list",
+			a:        []interface{}{map[string]interface{}{"port": "80"}},
+			b:        []interface{}{map[string]interface{}{"port": 80.0}},
+			expected: true,
+		},
+		{
+			name:     "objects with different keys",
+			rules:    []Rule{{Options: Options{IgnoreCase: true}}},
+			a:        map[string]interface{}{"a": "x"},
+			b:        map[string]interface{}{"b": "x"},
+			expected: false,
+		},
+		{name: "object and scalar", rules: []Rule{{Options: Options{IgnoreCase: true}}}, a: map[string]interface{}{}, b: "x", expected: false},
+		{name: "int values", rules: []Rule{{Options: Options{AbsTolerance: 1}}}, a: 1, b: int64(2), expected: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, New(tt.rules...).Equal(tt.path, tt.a, tt.b))
+		})
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  Rule
+		expectErr bool
+	}{
+		{
+			name:     "tolerance",
+			input:    "**.price=abs-tolerance:0.01",
+			expected: Rule{Pattern: "**.price", Options: Options{AbsTolerance: 0.01}},
+		},
+		{
+			name:  "several options",
+			input: "limits.* = rel-tolerance:0.1; numeric-strings;ignore-case;normalize-whitespace;semantic:duration",
+			expected: Rule{Pattern: "limits.*", Options: Options{
+				RelTolerance:        0.1,
+				NumericStrings:      true,
+				IgnoreCase:          true,
+				NormalizeWhitespace: true,
+				Semantics:           []Semantic{Duration},
+			}},
+		},
+		{name: "all semantics", input: "**=semantic:all", expected: Rule{Pattern: "**", Options: Options{Semantics: Semantics()}}},
+		{name: "missing pattern", input: "abs-tolerance:1", expectErr: true},
+		{name: "empty pattern", input: "=ignore-case", expectErr: true},
+		{name: "unknown option", input: "a=fuzzy", expectErr: true},
+		{name: "bad number", input: "a=abs-tolerance:x", expectErr: true},
+		{name: "negative number", input: "a=rel-tolerance:-1", expectErr: true},
+		{name: "unknown semantic", input: "a=semantic:color", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRule(tt.input)
+			if tt.expectErr {
+				require.ErrorIs(t, err, ErrInvalidRule)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, rule)
+		})
+	}
+}
+
+func TestParseRule_ScopedToPath(t *testing.T) {
+	rule, err := ParseRule("prices.*=abs-tolerance:0.01")
+	require.NoError(t, err)
+	c := New(rule)
+
+	require.True(t, c.Equal("prices.basic", 9.99, 9.995))
+	require.False(t, c.Equal("limits.basic", 9.99, 9.995))
+	require.False(t, c.Equal("prices", 9.99, 9.995))
+}
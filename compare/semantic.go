@@ -0,0 +1,297 @@
+package compare
+
+import (
+	"fmt"
+	"math"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Semantic recognizes values that can be written in several equivalent forms.
+type Semantic interface {
+	// Name identifies the comparator, e.g. "duration".
+	Name() string
+	// Compare returns ok=false when a or b is not a value of this kind.
+	// Otherwise it reports whether they are equivalent and, if not, a short
+	// note describing the change.
+	Compare(a, b interface{}) (equal bool, note string, ok bool)
+}
+
+var (
+	// Duration matches Go duration strings: "30s" equals "0.5m".
+	Duration Semantic = durationSemantic{}
+	// ByteSize matches quantities with binary or decimal suffixes as used by
+	// Kubernetes: "1Gi" equals "1024Mi", "500m" equals "0.5".
+	ByteSize Semantic = byteSizeSemantic{}
+	// SemVer matches semantic versions: "v1.2.3" equals "1.2.3+build.5".
+	SemVer Semantic = semverSemantic{}
+	// Timestamp matches RFC 3339 timestamps, equal when they are the same instant.
+	Timestamp Semantic = timestampSemantic{}
+	// IP matches IP addresses and CIDR prefixes in any notation.
+	IP Semantic = ipSemantic{}
+)
+
+// Semantics returns every built-in semantic comparator.
+func Semantics() []Semantic {
+	return []Semantic{Duration, ByteSize, SemVer, Timestamp, IP}
+}
+
+// SemanticByName returns the built-in comparator with the given name.
+func SemanticByName(name string) (Semantic, bool) {
+	for _, s := range Semantics() {
+		if s.Name() == name {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+type durationSemantic struct{}
+
+func (durationSemantic) Name() string { return "duration" }
+
+func (durationSemantic) Compare(a, b interface{}) (bool, string, bool) {
+	da, okA := parseDuration(a)
+	db, okB := parseDuration(b)
+	if !okA || !okB {
+		return false, "", false
+	}
+	return da == db, changeNote(float64(da), float64(db)), true
+}
+
+func parseDuration(v interface{}) (time.Duration, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return 0, false
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	return d, err == nil
+}
+
+type byteSizeSemantic struct{}
+
+func (byteSizeSemantic) Name() string { return "size" }
+
+// sizeSuffixes maps quantity suffixes to multipliers, longest first.
+var sizeSuffixes = []struct {
+	suffix     string
+	multiplier float64
+}{
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40}, {"PiB", 1 << 50}, {"EiB", 1 << 60},
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40}, {"Pi", 1 << 50}, {"Ei", 1 << 60},
+	{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12}, {"PB", 1e15}, {"EB", 1e18},
+	{"k", 1e3}, {"K", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12}, {"P", 1e15}, {"E", 1e18},
+	{"B", 1}, {"m", 1e-3},
+}
+
+func (byteSizeSemantic) Compare(a, b interface{}) (bool, string, bool) {
+	sa, suffixA, okA := parseSize(a)
+	sb, suffixB, okB := parseSize(b)
+	if !okA || !okB || (!suffixA && !suffixB) {
+		return false, "", false
+	}
+	return sa == sb, changeNote(sa, sb), true
+}
+
+// parseSize returns the value of a quantity and whether it had a suffix.
+// Plain numbers are accepted so that "0.5" can be compared with "500m".
+func parseSize(v interface{}) (float64, bool, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, false, true
+	case string:
+		s := strings.TrimSpace(val)
+		for _, unit := range sizeSuffixes {
+			if num, ok := strings.CutSuffix(s, unit.suffix); ok {
+				n, err := strconv.ParseFloat(num, 64)
+				if err != nil {
+					return 0, false, false
+				}
+				return n * unit.multiplier, true, true
+			}
+		}
+		n, err := strconv.ParseFloat(s, 64)
+		return n, false, err == nil
+	default:
+		return 0, false, false
+	}
+}
+
+type semverSemantic struct{}
+
+func (semverSemantic) Name() string { return "semver" }
+
+type semver struct {
+	major, minor, patch int
+	prerelease          string
+}
+
+func (semverSemantic) Compare(a, b interface{}) (bool, string, bool) {
+	va, okA := parseSemver(a)
+	vb, okB := parseSemver(b)
+	if !okA || !okB {
+		return false, "", false
+	}
+	if va == vb {
+		return true, "", true
+	}
+
+	direction := "upgrade"
+	if compareSemver(va, vb) > 0 {
+		direction = "downgrade"
+	}
+
+	switch {
+	case va.major != vb.major:
+		return false, "major " + direction, true
+	case va.minor != vb.minor:
+		return false, "minor " + direction, true
+	case va.patch != vb.patch:
+		return false, "patch " + direction, true
+	default:
+		return false, "prerelease " + direction, true
+	}
+}
+
+func parseSemver(v interface{}) (semver, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return semver{}, false
+	}
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	s, _, _ = strings.Cut(s, "+")
+	core, prerelease, _ := strings.Cut(s, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return semver{}, false
+	}
+
+	var nums [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+		nums[i] = n
+	}
+
+	return semver{major: nums[0], minor: nums[1], patch: nums[2], prerelease: prerelease}, true
+}
+
+func compareSemver(a, b semver) int {
+	for _, d := range []int{a.major - b.major, a.minor - b.minor, a.patch - b.patch} {
+		if d != 0 {
+			return d
+		}
+	}
+
+	// A version without prerelease ranks above the same version with one.
+	switch {
+	case a.prerelease == b.prerelease:
+		return 0
+	case a.prerelease == "":
+		return 1
+	case b.prerelease == "":
+		return -1
+	default:
+		return strings.Compare(a.prerelease, b.prerelease)
+	}
+}
+
+type timestampSemantic struct{}
+
+func (timestampSemantic) Name() string { return "timestamp" }
+
+func (timestampSemantic) Compare(a, b interface{}) (bool, string, bool) {
+	ta, okA := parseTimestamp(a)
+	tb, okB := parseTimestamp(b)
+	if !okA || !okB {
+		return false, "", false
+	}
+	if ta.Equal(tb) {
+		return true, "", true
+	}
+
+	delta := tb.Sub(ta)
+	if delta > 0 {
+		return false, "later by " + delta.String(), true
+	}
+	return false, "earlier by " + (-delta).String(), true
+}
+
+// parseTimestamp accepts RFC 3339 strings and the time.Time values YAML
+// produces for unquoted timestamps.
+func parseTimestamp(v interface{}) (time.Time, bool) {
+	switch val := v.(type) {
+	case time.Time:
+		return val, true
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(val))
+		return t, err == nil
+	default:
+		return time.Time{}, false
+	}
+}
+
+type ipSemantic struct{}
+
+func (ipSemantic) Name() string { return "ip" }
+
+func (ipSemantic) Compare(a, b interface{}) (bool, string, bool) {
+	sa, okA := a.(string)
+	sb, okB := b.(string)
+	if !okA || !okB {
+		return false, "", false
+	}
+
+	if pa, errA := netip.ParsePrefix(strings.TrimSpace(sa)); errA == nil {
+		pb, errB := netip.ParsePrefix(strings.TrimSpace(sb))
+		if errB != nil {
+			return false, "", false
+		}
+		pa = netip.PrefixFrom(pa.Addr().Unmap(), pa.Bits())
+		pb = netip.PrefixFrom(pb.Addr().Unmap(), pb.Bits())
+		if pa == pb {
+			return true, "", true
+		}
+		return false, prefixNote(pa, pb), true
+	}
+
+	ipA, errA := netip.ParseAddr(strings.TrimSpace(sa))
+	ipB, errB := netip.ParseAddr(strings.TrimSpace(sb))
+	if errA != nil || errB != nil {
+		return false, "", false
+	}
+	return ipA.Unmap() == ipB.Unmap(), "", true
+}
+
+func prefixNote(a, b netip.Prefix) string {
+	switch {
+	case a.Bits() > b.Bits():
+		return fmt.Sprintf("widened from /%d to /%d", a.Bits(), b.Bits())
+	case a.Bits() < b.Bits():
+		return fmt.Sprintf("narrowed from /%d to /%d", a.Bits(), b.Bits())
+	default:
+		return ""
+	}
+}
+
+// changeNote describes the relative change from a to b, e.g. "increased by 50%".
+func changeNote(a, b float64) string {
+	if a == b || a == 0 {
+		return ""
+	}
+
+	pct := (b - a) / math.Abs(a) * 100
+	if pct > 0 {
+		return "increased by " + formatPercent(pct)
+	}
+	return "decreased by " + formatPercent(-pct)
+}
+
+func formatPercent(p float64) string {
+	return strconv.FormatFloat(math.Round(p*100)/100, 'f', -1, 64) + "%"
+}
@@ -0,0 +1,128 @@
+package compare
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type semanticCase struct {
+	name   string
+	a, b   interface{}
+	equal  bool
+	note   string
+	notApp bool
+}
+
+func runSemanticCases(t *testing.T, s Semantic, tests []semanticCase) {
+	t.Helper()
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(s.Name()+"/"+tt.name, func(t *testing.T) {
+			equal, note, ok := s.Compare(tt.a, tt.b)
+			if tt.notApp {
+				require.False(t, ok)
+				return
+			}
+
+			require.True(t, ok)
+			require.Equal(t, tt.equal, equal)
+			require.Equal(t, tt.note, note)
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	runSemanticCases(t, Duration, []semanticCase{
+		{name: "equivalent", a: "30s", b: "0.5m", equal: true},
+		{name: "increased", a: "1m", b: "90s", note: "increased by 50%"},
+		{name: "decreased", a: "2h", b: "1h30m", note: "decreased by 25%"},
+		{name: "from zero", a: "0s", b: "5s"},
+		{name: "not a duration", a: "30s", b: "soon", notApp: true},
+		{name: "number", a: 30.0, b: "30s", notApp: true},
+	})
+}
+
+func TestByteSize(t *testing.T) {
+	runSemanticCases(t, ByteSize, []semanticCase{
+		{name: "binary suffixes", a: "1Gi", b: "1024Mi", equal: true},
+		{name: "decimal suffixes", a: "1G", b: "1000M", equal: true},
+		{name: "byte units", a: "1KiB", b: "1024B", equal: true},
+		{name: "milli and plain", a: "500m", b: "0.5", equal: true},
+		{name: "number and suffix", a: 2048.0, b: "2Ki", equal: true},
+		{name: "increased", a: "10Gi", b: "15Gi", note: "increased by 50%"},
+		{name: "no suffix on either side", a: "10", b: "20", notApp: true},
+		{name: "bad number", a: "xGi", b: "1Gi", notApp: true},
+		{name: "bool", a: true, b: "1Gi", notApp: true},
+	})
+}
+
+func TestSemVer(t *testing.T) {
+	runSemanticCases(t, SemVer, []semanticCase{
+		{name: "prefix and build metadata", a: "v1.2.3", b: "1.2.3+build.5", equal: true},
+		{name: "major upgrade", a: "1.2.3", b: "2.0.0", note: "major upgrade"},
+		{name: "minor downgrade", a: "1.3.0", b: "1.2.9", note: "minor downgrade"},
+		{name: "patch upgrade", a: "1.2.3", b: "1.2.4", note: "patch upgrade"},
+		{name: "release after prerelease", a: "1.2.3-rc.1", b: "1.2.3", note: "prerelease upgrade"},
+		{name: "prerelease downgrade", a: "1.2.3", b: "1.2.3-rc.1", note: "prerelease downgrade"},
+		{name: "prerelease order", a: "1.2.3-beta", b: "1.2.3-alpha", note: "prerelease downgrade"},
+		{name: "two parts", a: "1.2", b: "1.2.0", notApp: true},
+		{name: "not numeric", a: "1.x.0", b: "1.2.0", notApp: true},
+		{name: "number", a: 1.0, b: "1.0.0", notApp: true},
+	})
+}
+
+func TestTimestamp(t *testing.T) {
+	runSemanticCases(t, Timestamp, []semanticCase{
+		{name: "same instant in other zone", a: "2024-03-01T12:00:00Z", b: "2024-03-01T14:00:00+02:00", equal: true},
+		{name: "time value", a: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), b: "2024-03-01T12:00:00Z", equal: true},
+		{name: "later", a: "2024-03-01T12:00:00Z", b: "2024-03-01T13:30:00Z", note: "later by 1h30m0s"},
+		{name: "earlier", a: "2024-03-01T12:00:00Z", b: "2024-03-01T11:00:00Z", note: "earlier by 1h0m0s"},
+		{name: "not a timestamp", a: "2024-03-01", b: "2024-03-01T12:00:00Z", notApp: true},
+		{name: "number", a: 1.0, b: "2024-03-01T12:00:00Z", notApp: true},
+	})
+}
+
+func TestIP(t *testing.T) {
+	runSemanticCases(t, IP, []semanticCase{
+		{name: "mapped ipv4", a: "::ffff:10.0.0.1", b: "10.0.0.1", equal: true},
+		{name: "ipv6 notation", a: "2001:db8::1", b: "2001:0db8:0:0:0:0:0:1", equal: true},
+		{name: "different address", a: "10.0.0.1", b: "10.0.0.2"},
+		{name: "same prefix", a: "2001:db8::/32", b: "2001:0db8:0::/32", equal: true},
+		{name: "widened", a: "10.0.0.0/24", b: "10.0.0.0/16", note: "widened from /24 to /16"},
+		{name: "narrowed", a: "10.0.0.0/16", b: "10.0.0.0/24", note: "narrowed from /16 to /24"},
+		{name: "other network", a: "10.0.0.0/24", b: "10.1.0.0/24"},
+		{name: "prefix and address", a: "10.0.0.0/24", b: "10.0.0.1", notApp: true},
+		{name: "not an ip", a: "localhost", b: "10.0.0.1", notApp: true},
+		{name: "number", a: 1.0, b: "10.0.0.1", notApp: true},
+	})
+}
+
+func TestSemanticByName(t *testing.T) {
+	for _, s := range Semantics() {
+		found, ok := SemanticByName(s.Name())
+		require.True(t, ok)
+		require.Equal(t, s, found)
+	}
+
+	_, ok := SemanticByName("color")
+	require.False(t, ok)
+}
+
+func TestComparer_CompareWithSemantics(t *testing.T) {
+	c := New(Rule{Options: Options{Semantics: []Semantic{Duration, ByteSize}}})
+
+	equal, note := c.Compare("timeout", "30s", "0.5m")
+	require.True(t, equal)
+	require.Empty(t, note)
+
+	equal, note = c.Compare("memory", "1Gi", "2Gi")
+	require.False(t, equal)
+	require.Equal(t, "increased by 100%", note)
+
+	equal, note = c.Compare("name", "a", "b")
+	require.False(t, equal)
+	require.Empty(t, note)
+}
@@ -0,0 +1,65 @@
+package diff
+
+import "fmt"
+
+type NodeType string
+
+const (
+	NodeTypeAdded       NodeType = "added"
+	NodeTypeRemoved     NodeType = "removed"
+	NodeTypeChanged     NodeType = "changed"
+	NodeTypeTypeChanged NodeType = "typeChanged"
+	NodeTypeUnchanged   NodeType = "unchanged"
+	NodeTypeNested      NodeType = "nested"
+	NodeTypeMoved       NodeType = "moved"
+	NodeTypeRenamed     NodeType = "renamed"
+)
+
+// Value type names reported by TypeOf.
+const (
+	TypeNull    = "null"
+	TypeBoolean = "boolean"
+	TypeNumber  = "number"
+	TypeString  = "string"
+	TypeObject  = "object"
+	TypeArray   = "array"
+)
+
+// Node represents a node in the diff tree.
+//
+// Moved and renamed nodes sit at their new location and record the old
+// dotted path in From. They carry Value when the subtree is unchanged and
+// Children with the edits otherwise.
+type Node struct {
+	Type     NodeType    `json:"type"`
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value,omitempty"`
+	OldValue interface{} `json:"oldValue,omitempty"`
+	NewValue interface{} `json:"newValue,omitempty"`
+	OldType  string      `json:"oldType,omitempty"`
+	NewType  string      `json:"newType,omitempty"`
+	From     string      `json:"from,omitempty"`
+	// Annotation describes a change in words, e.g. "increased by 50%".
+	Annotation string  `json:"annotation,omitempty"`
+	Children   []*Node `json:"children,omitempty"`
+}
+
+// TypeOf returns the JSON type name of a parsed value.
+func TypeOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return TypeNull
+	case bool:
+		return TypeBoolean
+	case float64, float32, int, int64, int32, uint, uint64, uint32:
+		return TypeNumber
+	case string:
+		return TypeString
+	case map[string]interface{}:
+		return TypeObject
+	case []interface{}:
+		return TypeArray
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
@@ -0,0 +1,31 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{name: "nil", value: nil, expected: TypeNull},
+		{name: "bool", value: true, expected: TypeBoolean},
+		{name: "float64", value: 1.5, expected: TypeNumber},
+		{name: "int", value: 1, expected: TypeNumber},
+		{name: "string", value: "80", expected: TypeString},
+		{name: "object", value: map[string]interface{}{}, expected: TypeObject},
+		{name: "array", value: []interface{}{}, expected: TypeArray},
+		{name: "other", value: struct{}{}, expected: "struct {}"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, TypeOf(tt.value))
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package diff
+
+import "fmt"
+
+// Warning is a problem found in an input that does not stop the diff, such
+// as a schema violation or a duplicate key. Source names the input, e.g. a
+// file path, and Line is the 1-based line in it when known.
+type Warning struct {
+	Source  string `json:"source,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+func (w Warning) String() string {
+	s := w.Message
+	if w.Path != "" {
+		s = fmt.Sprintf("%s: %s", w.Path, s)
+	}
+
+	switch {
+	case w.Source != "" && w.Line > 0:
+		s = fmt.Sprintf("%s:%d: %s", w.Source, w.Line, s)
+	case w.Source != "":
+		s = fmt.Sprintf("%s: %s", w.Source, s)
+	case w.Line > 0:
+		s = fmt.Sprintf("line %d: %s", w.Line, s)
+	}
+	return s
+}
@@ -0,0 +1,28 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarning_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		warning  Warning
+		expected string
+	}{
+		{name: "message only", warning: Warning{Message: "bad"}, expected: "bad"},
+		{name: "with path", warning: Warning{Path: "a.b", Message: "bad"}, expected: "a.b: bad"},
+		{name: "with source", warning: Warning{Source: "f.yml", Path: "a", Message: "bad"}, expected: "f.yml: a: bad"},
+		{name: "with source and line", warning: Warning{Source: "f.yml", Line: 3, Path: "a", Message: "bad"}, expected: "f.yml:3: a: bad"},
+		{name: "with line only", warning: Warning{Line: 3, Message: "bad"}, expected: "line 3: bad"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.warning.String())
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package formatter
+
+import (
+	"code/diff"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	FormatStylish  = "stylish"
+	FormatPlain    = "plain"
+	FormatJSON     = "json"
+	FormatTemplate = "template"
+	FormatHTML     = "html"
+)
+
+// SupportedFormats lists the built-in formats.
+var SupportedFormats = []string{
+	FormatStylish,
+	FormatPlain,
+	FormatJSON,
+	FormatTemplate,
+	FormatHTML,
+}
+
+var (
+	ErrUnknownFormat = errors.New("unknown format")
+	ErrInvalidName   = errors.New("formatter name cannot be empty")
+	ErrNilFactory    = errors.New("formatter factory cannot be nil")
+)
+
+// Formatter formats a diff tree into a string representation.
+type Formatter interface {
+	Format(nodes []*diff.Node) (string, error)
+}
+
+// StreamFormatter is implemented by formatters that can write their output
+// incrementally instead of building it in memory.
+type StreamFormatter interface {
+	Formatter
+	FormatTo(w io.Writer, nodes []*diff.Node) error
+}
+
+// FormatTo writes the formatted nodes to w, streaming when f supports it.
+func FormatTo(w io.Writer, f Formatter, nodes []*diff.Node) error {
+	if sf, ok := f.(StreamFormatter); ok {
+		return sf.FormatTo(w, nodes)
+	}
+
+	result, err := f.Format(nodes)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, result)
+	return err
+}
+
+// Options holds settings for formatters that need more than a name.
+type Options struct {
+	// TemplatePath is the text/template file used by the template formatter.
+	TemplatePath string
+	// Collapse makes the stylish formatter keep only Context unchanged keys
+	// around each change and omit fully unchanged subtrees.
+	Collapse bool
+	Context  int
+	// QuoteStrings makes the stylish formatter quote strings that could be
+	// mistaken for other values.
+	QuoteStrings bool
+	// WordDiffLen, when positive, makes stylish and plain output compare
+	// changed single-line strings of at least this many characters word by
+	// word.
+	WordDiffLen int
+	// Warnings are problems found in the inputs, which the built-in
+	// formatters other than template list after the diff.
+	Warnings []diff.Warning
+}
+
+type Option func(*Options)
+
+// WithTemplatePath sets the template file for the template formatter.
+func WithTemplatePath(path string) Option {
+	return func(o *Options) {
+		o.TemplatePath = path
+	}
+}
+
+// WithContext keeps n unchanged keys around each change in stylish output
+// and collapses the rest into "... N unchanged keys ..." markers.
+func WithContext(n int) Option {
+	return func(o *Options) {
+		o.Collapse = true
+		o.Context = max(n, 0)
+	}
+}
+
+// WithQuotedStrings quotes empty, padded, multi-line and number-, boolean-
+// or null-like strings in stylish output.
+func WithQuotedStrings() Option {
+	return func(o *Options) {
+		o.QuoteStrings = true
+	}
+}
+
+// WithWordDiff shows changes to single-line strings of at least n characters
+// as word diffs. Multi-line strings always get a line diff.
+func WithWordDiff(n int) Option {
+	return func(o *Options) {
+		o.WordDiffLen = n
+	}
+}
+
+// WithWarnings passes problems found in the inputs to the formatter.
+func WithWarnings(warnings []diff.Warning) Option {
+	return func(o *Options) {
+		o.Warnings = warnings
+	}
+}
+
+// Factory creates a formatter for the given options.
+type Factory func(opts Options) (Formatter, error)
+
+// Registry maps format names to formatter factories.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry returns a registry with the built-in formats registered.
+func NewRegistry() *Registry {
+	r := &Registry{
+		factories: make(map[string]Factory),
+	}
+
+	r.mustRegister(FormatStylish, func(o Options) (Formatter, error) {
+		return &StylishFormatter{
+			Collapse:     o.Collapse,
+			Context:      o.Context,
+			QuoteStrings: o.QuoteStrings,
+			WordDiffLen:  o.WordDiffLen,
+			Warnings:     o.Warnings,
+		}, nil
+	})
+	r.mustRegister(FormatPlain, func(o Options) (Formatter, error) {
+		return &PlainFormatter{WordDiffLen: o.WordDiffLen, Warnings: o.Warnings}, nil
+	})
+	r.mustRegister(FormatJSON, func(o Options) (Formatter, error) { return &JSONFormatter{Warnings: o.Warnings}, nil })
+	r.mustRegister(FormatHTML, func(o Options) (Formatter, error) {
+		return &HTMLFormatter{WordDiffLen: o.WordDiffLen, Warnings: o.Warnings}, nil
+	})
+	r.mustRegister(FormatTemplate, func(o Options) (Formatter, error) {
+		f, err := NewTemplateFormatter(o.TemplatePath)
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+	})
+
+	return r
+}
+
+// Register adds a formatter factory under name, replacing any previous one.
+func (r *Registry) Register(name string, factory Factory) error {
+	if name == "" {
+		return ErrInvalidName
+	}
+	if factory == nil {
+		return fmt.Errorf("%w: %s", ErrNilFactory, name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.factories[name] = factory
+	return nil
+}
+
+func (r *Registry) mustRegister(name string, factory Factory) {
+	if err := r.Register(name, factory); err != nil {
+		panic(err)
+	}
+}
+
+// Get returns a formatter by its name. An empty name selects stylish.
+func (r *Registry) Get(name string, opts ...Option) (Formatter, error) {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if name == "" {
+		name = FormatStylish
+	}
+
+	r.mu.RLock()
+	factory, ok := r.factories[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s (allowed: %s)", ErrUnknownFormat, name, strings.Join(r.Names(), ", "))
+	}
+
+	return factory(o)
+}
+
+// Names returns the registered format names in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var defaultRegistry = NewRegistry()
+
+// Default returns the package-level registry used by GetFormatter.
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// RegisterFormatter adds a formatter factory to the default registry.
+func RegisterFormatter(name string, factory Factory) error {
+	return defaultRegistry.Register(name, factory)
+}
+
+// GetFormatter returns a formatter implementation by its name from the default registry.
+func GetFormatter(name string, opts ...Option) (Formatter, error) {
+	return defaultRegistry.Get(name, opts...)
+}
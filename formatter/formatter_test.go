@@ -0,0 +1,131 @@
+package formatter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"code/diff"
+
+	"github.com/stretchr/testify/require"
+)
+
+type getFormatterCase struct {
+	name      string
+	format    string
+	opts      []Option
+	expectErr error
+}
+
+func TestGetFormatter(t *testing.T) {
+	tests := []getFormatterCase{
+		{name: "default", format: ""},
+		{name: "stylish", format: "stylish"},
+		{name: "plain", format: "plain"},
+		{name: "json", format: "json"},
+		{name: "template without path", format: "template", expectErr: ErrTemplateRequired},
+		{name: "invalid", format: "xml", expectErr: ErrUnknownFormat},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := GetFormatter(tt.format, tt.opts...)
+			if tt.expectErr != nil {
+				require.Error(t, err)
+				require.Nil(t, f)
+				require.True(t, errors.Is(err, tt.expectErr))
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, f)
+		})
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	f, err := GetFormatter(FormatStylish, WithContext(2))
+	require.NoError(t, err)
+	require.Equal(t, &StylishFormatter{Collapse: true, Context: 2}, f)
+
+	f, err = GetFormatter(FormatStylish, WithContext(-1))
+	require.NoError(t, err)
+	require.Equal(t, &StylishFormatter{Collapse: true, Context: 0}, f)
+}
+
+type upperFormatter struct{}
+
+func (f *upperFormatter) Format(nodes []*diff.Node) (string, error) {
+	return "custom", nil
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewRegistry()
+	factory := func(Options) (Formatter, error) { return &upperFormatter{}, nil }
+
+	require.ErrorIs(t, r.Register("", factory), ErrInvalidName)
+	require.ErrorIs(t, r.Register("custom", nil), ErrNilFactory)
+	require.NoError(t, r.Register("custom", factory))
+	require.Contains(t, r.Names(), "custom")
+
+	f, err := r.Get("custom")
+	require.NoError(t, err)
+	out, err := f.Format(nil)
+	require.NoError(t, err)
+	require.Equal(t, "custom", out)
+
+	_, err = NewRegistry().Get("custom")
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	name := "test-registered"
+	require.NoError(t, RegisterFormatter(name, func(Options) (Formatter, error) { return &upperFormatter{}, nil }))
+
+	f, err := GetFormatter(name)
+	require.NoError(t, err)
+	require.IsType(t, &upperFormatter{}, f)
+	require.Contains(t, Default().Names(), name)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestFormatTo(t *testing.T) {
+	nodes := []*diff.Node{
+		{Type: diff.NodeTypeAdded, Key: "key1", Value: "value1"},
+		{
+			Type: diff.NodeTypeNested,
+			Key:  "group",
+			Children: []*diff.Node{
+				{Type: diff.NodeTypeChanged, Key: "child", OldValue: 1.0, NewValue: nil},
+				{Type: diff.NodeTypeNested, Key: "empty"},
+			},
+		},
+	}
+
+	formatters := map[string]Formatter{
+		"stylish": &StylishFormatter{},
+		"plain":   &PlainFormatter{},
+		"json":    &JSONFormatter{},
+		"custom":  &upperFormatter{},
+	}
+
+	for name, f := range formatters {
+		f := f
+		t.Run(name, func(t *testing.T) {
+			expected, err := f.Format(nodes)
+			require.NoError(t, err)
+
+			var sb strings.Builder
+			require.NoError(t, FormatTo(&sb, f, nodes))
+			require.Equal(t, expected, sb.String())
+
+			require.Error(t, FormatTo(failingWriter{}, f, nodes))
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package formatter
+
+import (
+	"bufio"
+	"code/diff"
+	"code/internal/textdiff"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gendiff</title>
+<style>
+body { font-family: monospace; }
+ul.diff { list-style: none; padding-left: 1.5em; }
+li.added { background: #e6ffec; }
+li.removed { background: #ffebe9; }
+li.unchanged { color: #57606a; }
+del { background: #ffc0c0; }
+ins { background: #abf2bc; text-decoration: none; }
+pre { margin: 0.2em 0 0.2em 1.5em; }
+.hunk { color: #6e7781; }
+.note, .from { color: #6e7781; font-style: italic; }
+</style>
+</head>
+<body>
+`
+
+// HTMLFormatter writes the diff tree as a standalone HTML page of nested
+// lists. Changed multi-line strings are shown as line diffs, and single-line
+// ones of at least WordDiffLen characters as word diffs when it is positive.
+// Warnings, when present, are listed after the diff.
+type HTMLFormatter struct {
+	WordDiffLen int
+	Warnings    []diff.Warning
+}
+
+func (f *HTMLFormatter) Format(nodes []*diff.Node) (string, error) {
+	var sb strings.Builder
+	if err := f.FormatTo(&sb, nodes); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func (f *HTMLFormatter) FormatTo(w io.Writer, nodes []*diff.Node) error {
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString(htmlHeader)
+	f.writeNodes(bw, nodes)
+	if len(f.Warnings) > 0 {
+		bw.WriteString("<h2>Warnings</h2>\n<ul class=\"warnings\">\n")
+		for _, warning := range f.Warnings {
+			fmt.Fprintf(bw, "<li>%s</li>\n", html.EscapeString(warning.String()))
+		}
+		bw.WriteString("</ul>\n")
+	}
+	bw.WriteString("</body>\n</html>\n")
+
+	return bw.Flush()
+}
+
+func (f *HTMLFormatter) writeNodes(w *bufio.Writer, nodes []*diff.Node) {
+	w.WriteString("<ul class=\"diff\">\n")
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		key := html.EscapeString(node.Key)
+
+		switch node.Type {
+		case diff.NodeTypeAdded:
+			fmt.Fprintf(w, "<li class=\"added\">+ %s: %s</li>\n", key, htmlValue(node.Value))
+
+		case diff.NodeTypeRemoved:
+			fmt.Fprintf(w, "<li class=\"removed\">- %s: %s</li>\n", key, htmlValue(node.Value))
+
+		case diff.NodeTypeChanged:
+			if oldText, newText, multiline, ok := textChange(node, f.WordDiffLen); ok {
+				f.writeText(w, key, oldText, newText, multiline)
+				continue
+			}
+			fmt.Fprintf(w, "<li class=\"changed\">~ %s: <del>%s</del> <ins>%s</ins>%s</li>\n",
+				key, htmlValue(node.OldValue), htmlValue(node.NewValue), htmlNote(node.Annotation))
+
+		case diff.NodeTypeTypeChanged:
+			fmt.Fprintf(w, "<li class=\"changed\">~ %s: <del>%s (%s)</del> <ins>%s (%s)</ins></li>\n",
+				key, htmlValue(node.OldValue), html.EscapeString(node.OldType),
+				htmlValue(node.NewValue), html.EscapeString(node.NewType))
+
+		case diff.NodeTypeUnchanged:
+			fmt.Fprintf(w, "<li class=\"unchanged\">&nbsp; %s: %s</li>\n", key, htmlValue(node.Value))
+
+		case diff.NodeTypeNested:
+			fmt.Fprintf(w, "<li class=\"nested\">&nbsp; %s\n", key)
+			f.writeNodes(w, node.Children)
+			w.WriteString("</li>\n")
+
+		case diff.NodeTypeMoved, diff.NodeTypeRenamed:
+			fmt.Fprintf(w, "<li class=\"%s\">&gt; %s <span class=\"from\">(%s from %s)</span>",
+				node.Type, key, node.Type, html.EscapeString(node.From))
+			if hasChildren(node) {
+				w.WriteString("\n")
+				f.writeNodes(w, node.Children)
+			} else {
+				fmt.Fprintf(w, ": %s", htmlValue(node.Value))
+			}
+			w.WriteString("</li>\n")
+		}
+	}
+	w.WriteString("</ul>\n")
+}
+
+// writeText renders a changed string as the hunks of its line diff, or as an
+// inline word diff.
+func (f *HTMLFormatter) writeText(w *bufio.Writer, key, oldText, newText string, multiline bool) {
+	if !multiline {
+		fmt.Fprintf(w, "<li class=\"changed\">~ %s: ", key)
+		writeHTMLOps(w, textdiff.Words(oldText, newText), false)
+		w.WriteString("</li>\n")
+		return
+	}
+
+	fmt.Fprintf(w, "<li class=\"changed\">~ %s:\n<pre>", key)
+	for _, hunk := range textdiff.Hunks(textdiff.Lines(oldText, newText), textContext) {
+		fmt.Fprintf(w, "<span class=\"hunk\">@@ -%d,%d +%d,%d @@</span>\n",
+			hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines)
+		writeHTMLOps(w, hunk.Ops, true)
+	}
+	w.WriteString("</pre></li>\n")
+}
+
+// writeHTMLOps writes ops with deletions in <del> and insertions in <ins>.
+// Lines are written one per line after a "-", "+" or " " marker.
+func writeHTMLOps(w *bufio.Writer, ops []textdiff.Op, lines bool) {
+	for _, op := range ops {
+		text := html.EscapeString(op.Text)
+		if lines {
+			marker := " "
+			switch op.Kind {
+			case textdiff.Delete:
+				marker = "-"
+			case textdiff.Insert:
+				marker = "+"
+			}
+			text = marker + " " + text + "\n"
+		}
+
+		switch op.Kind {
+		case textdiff.Delete:
+			fmt.Fprintf(w, "<del>%s</del>", text)
+		case textdiff.Insert:
+			fmt.Fprintf(w, "<ins>%s</ins>", text)
+		default:
+			w.WriteString(text)
+		}
+	}
+}
+
+// htmlValue renders scalars like the stylish format and objects and arrays
+// as indented JSON.
+func htmlValue(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return html.EscapeString(fmt.Sprint(v))
+		}
+		return "<pre>" + html.EscapeString(string(data)) + "</pre>"
+	}
+	return html.EscapeString(formatSimpleValue(v))
+}
+
+func htmlNote(note string) string {
+	if note == "" {
+		return ""
+	}
+	return " <span class=\"note\">(" + html.EscapeString(note) + ")</span>"
+}
@@ -0,0 +1,96 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"code/diff"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLFormatter_Format(t *testing.T) {
+	long := strings.Repeat("word ", 20)
+
+	tests := []struct {
+		name          string
+		formatter     *HTMLFormatter
+		nodes         []*diff.Node
+		expectContain []string
+		expectMissing []string
+	}{
+		{
+			name:      "node kinds",
+			formatter: &HTMLFormatter{},
+			nodes: []*diff.Node{
+				{Type: diff.NodeTypeAdded, Key: "a<b>", Value: "x & y"},
+				{Type: diff.NodeTypeRemoved, Key: "list", Value: []interface{}{1.0}},
+				{Type: diff.NodeTypeChanged, Key: "timeout", OldValue: "1m", NewValue: "90s", Annotation: "increased by 50%"},
+				{Type: diff.NodeTypeTypeChanged, Key: "port", OldValue: "80", NewValue: 80.0, OldType: "string", NewType: "number"},
+				{Type: diff.NodeTypeNested, Key: "group", Children: []*diff.Node{
+					{Type: diff.NodeTypeUnchanged, Key: "kept", Value: true},
+				}},
+				{Type: diff.NodeTypeMoved, Key: "db", From: "old.db", Value: "x"},
+			},
+			expectContain: []string{
+				"<!DOCTYPE html>",
+				`<li class="added">+ a&lt;b&gt;: x &amp; y</li>`,
+				"<li class=\"removed\">- list: <pre>[\n  1\n]</pre></li>",
+				`<li class="changed">~ timeout: <del>1m</del> <ins>90s</ins> <span class="note">(increased by 50%)</span></li>`,
+				`<li class="changed">~ port: <del>80 (string)</del> <ins>80 (number)</ins></li>`,
+				"<li class=\"nested\">&nbsp; group\n<ul class=\"diff\">\n<li class=\"unchanged\">&nbsp; kept: true</li>\n</ul>\n</li>",
+				`<li class="moved">&gt; db <span class="from">(moved from old.db)</span>: x</li>`,
+				"</body>\n</html>\n",
+			},
+			expectMissing: []string{"Warnings"},
+		},
+		{
+			name:      "line diff",
+			formatter: &HTMLFormatter{},
+			nodes: []*diff.Node{
+				{Type: diff.NodeTypeChanged, Key: "script", OldValue: "a\n<b>\nc\n", NewValue: "a\nB\nc\n"},
+			},
+			expectContain: []string{
+				"<li class=\"changed\">~ script:\n<pre><span class=\"hunk\">@@ -1,3 +1,3 @@</span>\n" +
+					"  a\n<del>- &lt;b&gt;\n</del><ins>+ B\n</ins>  c\n</pre></li>",
+			},
+		},
+		{
+			name:      "long line without word diff",
+			formatter: &HTMLFormatter{},
+			nodes:     []*diff.Node{{Type: diff.NodeTypeChanged, Key: "text", OldValue: long, NewValue: long + "more"}},
+			expectContain: []string{
+				`~ text: <del>` + long + `</del> <ins>` + long + `more</ins>`,
+			},
+		},
+		{
+			name:      "word diff",
+			formatter: &HTMLFormatter{WordDiffLen: 80},
+			nodes:     []*diff.Node{{Type: diff.NodeTypeChanged, Key: "text", OldValue: long + "old", NewValue: long + "new"}},
+			expectContain: []string{
+				`~ text: ` + long + `<del>old</del><ins>new</ins></li>`,
+			},
+		},
+		{
+			name:      "warnings",
+			formatter: &HTMLFormatter{Warnings: []diff.Warning{{Source: "a.json", Line: 3, Path: "x", Message: "duplicate <key>"}}},
+			expectContain: []string{
+				"<h2>Warnings</h2>\n<ul class=\"warnings\">\n<li>a.json:3: x: duplicate &lt;key&gt;</li>\n</ul>",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.formatter.Format(tt.nodes)
+			require.NoError(t, err)
+			for _, substr := range tt.expectContain {
+				require.Contains(t, result, substr)
+			}
+			for _, substr := range tt.expectMissing {
+				require.NotContains(t, result, substr)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package formatter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"code/diff"
+)
+
+const (
+	jsonTypeRoot        = "root"
+	jsonTypeAdded       = "added"
+	jsonTypeDeleted     = "deleted"
+	jsonTypeChanged     = "changed"
+	jsonTypeTypeChanged = "typeChanged"
+	jsonTypeUnchanged   = "unchanged"
+	jsonTypeNested      = "nested"
+	jsonTypeMoved       = "moved"
+	jsonTypeRenamed     = "renamed"
+
+	jsonIndent = "  "
+)
+
+// JSONFormatter writes the diff tree as JSON. Warnings, when present, are
+// listed under "warnings" in the root object.
+type JSONFormatter struct {
+	Warnings []diff.Warning
+}
+
+type jsonNode struct {
+	Key      string         `json:"key"`
+	Type     string         `json:"type"`
+	Value1   interface{}    `json:"value1,omitempty"`
+	Value2   interface{}    `json:"value2,omitempty"`
+	Type1    string         `json:"type1,omitempty"`
+	Type2    string         `json:"type2,omitempty"`
+	From     string         `json:"from,omitempty"`
+	Note     string         `json:"annotation,omitempty"`
+	Children []*jsonNode    `json:"children,omitempty"`
+	Warnings []diff.Warning `json:"warnings,omitempty"`
+}
+
+func (f *JSONFormatter) Format(nodes []*diff.Node) (string, error) {
+	var sb strings.Builder
+	if err := f.FormatTo(&sb, nodes); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// FormatTo writes the same document as Format, encoding one node at a time
+// so that the whole tree is never held in memory as JSON.
+func (f *JSONFormatter) FormatTo(w io.Writer, nodes []*diff.Node) error {
+	bw := bufio.NewWriter(w)
+
+	root := &jsonNode{Key: "", Type: jsonTypeRoot, Warnings: f.Warnings}
+	if err := f.writeNode(bw, root, nodes, ""); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// writeNode writes jNode with children produced lazily from nodes. The output
+// matches json.MarshalIndent(jNode, indent, "  ").
+func (f *JSONFormatter) writeNode(w *bufio.Writer, jNode *jsonNode, nodes []*diff.Node, indent string) error {
+	if !anyNode(nodes) {
+		data, err := json.MarshalIndent(jNode, indent, jsonIndent)
+		if err != nil {
+			return fmt.Errorf("marshal diff to json: %w", err)
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	inner := indent + jsonIndent
+	key, err := json.Marshal(jNode.Key)
+	if err != nil {
+		return fmt.Errorf("marshal diff to json: %w", err)
+	}
+	typ, err := json.Marshal(jNode.Type)
+	if err != nil {
+		return fmt.Errorf("marshal diff to json: %w", err)
+	}
+	fmt.Fprintf(w, "{\n%s\"key\": %s,\n%s\"type\": %s,\n", inner, key, inner, typ)
+	if jNode.From != "" {
+		from, err := json.Marshal(jNode.From)
+		if err != nil {
+			return fmt.Errorf("marshal diff to json: %w", err)
+		}
+		fmt.Fprintf(w, "%s\"from\": %s,\n", inner, from)
+	}
+	fmt.Fprintf(w, "%s\"children\": [\n", inner)
+
+	childIndent := inner + jsonIndent
+	first := true
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		if !first {
+			w.WriteString(",\n")
+		}
+		first = false
+
+		w.WriteString(childIndent)
+		var grandChildren []*diff.Node
+		if hasChildren(node) {
+			grandChildren = node.Children
+		}
+		if err := f.writeNode(w, f.convertNode(node), grandChildren, childIndent); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "\n%s]", inner)
+	if len(jNode.Warnings) > 0 {
+		warnings, err := json.MarshalIndent(jNode.Warnings, inner, jsonIndent)
+		if err != nil {
+			return fmt.Errorf("marshal diff to json: %w", err)
+		}
+		fmt.Fprintf(w, ",\n%s\"warnings\": %s", inner, warnings)
+	}
+	_, err = fmt.Fprintf(w, "\n%s}", indent)
+	return err
+}
+
+func (f *JSONFormatter) convertNode(node *diff.Node) *jsonNode {
+	jNode := &jsonNode{
+		Key: node.Key,
+	}
+
+	switch node.Type {
+	case diff.NodeTypeAdded:
+		jNode.Type = jsonTypeAdded
+		jNode.Value2 = node.Value
+	case diff.NodeTypeRemoved:
+		jNode.Type = jsonTypeDeleted
+		jNode.Value1 = node.Value
+	case diff.NodeTypeChanged:
+		jNode.Type = jsonTypeChanged
+		jNode.Value1 = node.OldValue
+		jNode.Value2 = node.NewValue
+		jNode.Note = node.Annotation
+	case diff.NodeTypeTypeChanged:
+		jNode.Type = jsonTypeTypeChanged
+		jNode.Value1 = node.OldValue
+		jNode.Value2 = node.NewValue
+		jNode.Type1 = node.OldType
+		jNode.Type2 = node.NewType
+	case diff.NodeTypeMoved, diff.NodeTypeRenamed:
+		jNode.Type = jsonTypeMoved
+		if node.Type == diff.NodeTypeRenamed {
+			jNode.Type = jsonTypeRenamed
+		}
+		jNode.From = node.From
+		jNode.Value1 = node.Value
+	case diff.NodeTypeNested:
+		// Children are written by writeNode as they are converted.
+		jNode.Type = jsonTypeNested
+	case diff.NodeTypeUnchanged:
+		jNode.Type = jsonTypeUnchanged
+		jNode.Value1 = node.Value
+	default:
+		jNode.Type = string(node.Type)
+		jNode.Value1 = node.Value
+	}
+
+	return jNode
+}
+
+// anyNode reports whether nodes holds a node to write; nil entries are
+// skipped, and without nodes "children" is omitted.
+func anyNode(nodes []*diff.Node) bool {
+	for _, node := range nodes {
+		if node != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hasChildren reports whether node content is a list of child nodes rather
+// than a value.
+func hasChildren(node *diff.Node) bool {
+	switch node.Type {
+	case diff.NodeTypeNested:
+		return true
+	case diff.NodeTypeMoved, diff.NodeTypeRenamed:
+		return len(node.Children) > 0
+	default:
+		return false
+	}
+}
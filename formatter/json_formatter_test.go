@@ -0,0 +1,187 @@
+package formatter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"code/diff"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONFormatter_Format(t *testing.T) {
+	tests := []struct {
+		name        string
+		nodes       []*diff.Node
+		expectErr   bool
+		errContains string
+		assertFunc  func(t *testing.T, root *jsonNode)
+	}{
+		{
+			name: "non empty",
+			nodes: []*diff.Node{
+				{Type: diff.NodeTypeAdded, Key: "key1", Value: "value1"},
+				{
+					Type: diff.NodeTypeNested,
+					Key:  "parent",
+					Children: []*diff.Node{
+						{Type: diff.NodeTypeChanged, Key: "child", OldValue: "old", NewValue: "new"},
+					},
+				},
+			},
+			assertFunc: func(t *testing.T, root *jsonNode) {
+				require.Equal(t, jsonTypeRoot, root.Type)
+				require.Len(t, root.Children, 2)
+
+				added := root.Children[0]
+				require.Equal(t, "key1", added.Key)
+				require.Equal(t, jsonTypeAdded, added.Type)
+				require.Nil(t, added.Children)
+				require.Nil(t, added.Value1)
+				require.Equal(t, "value1", added.Value2)
+
+				parent := root.Children[1]
+				require.Equal(t, jsonTypeNested, parent.Type)
+				require.Len(t, parent.Children, 1)
+				child := parent.Children[0]
+				require.Equal(t, jsonTypeChanged, child.Type)
+				require.Equal(t, "old", child.Value1)
+				require.Equal(t, "new", child.Value2)
+			},
+		},
+		{
+			name: "annotated",
+			nodes: []*diff.Node{
+				{Type: diff.NodeTypeChanged, Key: "timeout", OldValue: "1m", NewValue: "90s", Annotation: "increased by 50%"},
+			},
+			assertFunc: func(t *testing.T, root *jsonNode) {
+				require.Len(t, root.Children, 1)
+
+				timeout := root.Children[0]
+				require.Equal(t, jsonTypeChanged, timeout.Type)
+				require.Equal(t, "1m", timeout.Value1)
+				require.Equal(t, "90s", timeout.Value2)
+				require.Equal(t, "increased by 50%", timeout.Note)
+			},
+		},
+		{
+			name: "type changed",
+			nodes: []*diff.Node{
+				{Type: diff.NodeTypeTypeChanged, Key: "port", OldValue: "80", NewValue: 80.0, OldType: "string", NewType: "number"},
+			},
+			assertFunc: func(t *testing.T, root *jsonNode) {
+				require.Len(t, root.Children, 1)
+
+				port := root.Children[0]
+				require.Equal(t, jsonTypeTypeChanged, port.Type)
+				require.Equal(t, "80", port.Value1)
+				require.Equal(t, 80.0, port.Value2)
+				require.Equal(t, "string", port.Type1)
+				require.Equal(t, "number", port.Type2)
+			},
+		},
+		{
+			name: "empty nodes",
+			assertFunc: func(t *testing.T, root *jsonNode) {
+				require.Equal(t, jsonTypeRoot, root.Type)
+				require.Empty(t, root.Children)
+			},
+		},
+		{
+			name: "marshal error",
+			nodes: []*diff.Node{
+				{Type: diff.NodeTypeAdded, Key: "invalid", Value: make(chan struct{})},
+			},
+			expectErr:   true,
+			errContains: "marshal diff to json",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			f := &JSONFormatter{}
+			result, err := f.Format(tt.nodes)
+
+			if tt.expectErr {
+				require.Empty(t, result)
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var root jsonNode
+			require.NoError(t, json.Unmarshal([]byte(result), &root))
+
+			require.NotNil(t, tt.assertFunc, "assertFunc must be provided for non-error cases")
+			tt.assertFunc(t, &root)
+		})
+	}
+}
+
+func TestJSONFormatter_FormatMatchesMarshalIndent(t *testing.T) {
+	nodes := []*diff.Node{
+		{Type: diff.NodeTypeRemoved, Key: "a<b>", Value: []interface{}{1.0, "x"}},
+		{
+			Type: diff.NodeTypeNested,
+			Key:  "outer",
+			Children: []*diff.Node{
+				{Type: diff.NodeTypeNested, Key: "inner", Children: []*diff.Node{
+					{Type: diff.NodeTypeUnchanged, Key: "k", Value: map[string]interface{}{"z": true}},
+				}},
+				{Type: diff.NodeTypeNested, Key: "empty"},
+				{Type: diff.NodeTypeNested, Key: "nil children", Children: []*diff.Node{nil}},
+			},
+		},
+	}
+
+	expected := &jsonNode{Type: jsonTypeRoot, Children: []*jsonNode{
+		{Key: "a<b>", Type: jsonTypeDeleted, Value1: []interface{}{1.0, "x"}},
+		{Key: "outer", Type: jsonTypeNested, Children: []*jsonNode{
+			{Key: "inner", Type: jsonTypeNested, Children: []*jsonNode{
+				{Key: "k", Type: jsonTypeUnchanged, Value1: map[string]interface{}{"z": true}},
+			}},
+			{Key: "empty", Type: jsonTypeNested},
+			{Key: "nil children", Type: jsonTypeNested},
+		}},
+	}}
+	data, err := json.MarshalIndent(expected, "", "  ")
+	require.NoError(t, err)
+
+	result, err := (&JSONFormatter{}).Format(nodes)
+	require.NoError(t, err)
+	require.Equal(t, string(data), result)
+}
+
+func TestJSONFormatter_Warnings(t *testing.T) {
+	warnings := []diff.Warning{
+		{Source: "a.json", Line: 3, Path: "port", Message: "duplicate key"},
+	}
+
+	tests := []struct {
+		name  string
+		nodes []*diff.Node
+	}{
+		{name: "no changes", nodes: nil},
+		{name: "with changes", nodes: []*diff.Node{{Type: diff.NodeTypeAdded, Key: "port", Value: 80.0}}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			expected := &jsonNode{Type: jsonTypeRoot, Warnings: warnings}
+			for _, node := range tt.nodes {
+				expected.Children = append(expected.Children, (&JSONFormatter{}).convertNode(node))
+			}
+			data, err := json.MarshalIndent(expected, "", "  ")
+			require.NoError(t, err)
+
+			result, err := (&JSONFormatter{Warnings: warnings}).Format(tt.nodes)
+			require.NoError(t, err)
+			require.Equal(t, string(data), result)
+			require.Contains(t, result, `"line": 3`)
+		})
+	}
+}
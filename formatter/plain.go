@@ -0,0 +1,168 @@
+package formatter
+
+import (
+	"bufio"
+	"code/diff"
+	"code/internal/textdiff"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PlainFormatter writes one line per changed property. Changed multi-line
+// strings are reported as a count of changed lines, and single-line ones of
+// at least WordDiffLen characters as a count of changed words when it is
+// positive. Warnings, when present, follow the diff after a blank line.
+type PlainFormatter struct {
+	WordDiffLen int
+	Warnings    []diff.Warning
+}
+
+func (f *PlainFormatter) Format(nodes []*diff.Node) (string, error) {
+	var sb strings.Builder
+	if err := f.FormatTo(&sb, nodes); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func (f *PlainFormatter) FormatTo(w io.Writer, nodes []*diff.Node) error {
+	lw := &lineWriter{w: bufio.NewWriter(w)}
+	f.writePlainLines(lw, nodes, "")
+	if len(f.Warnings) > 0 && lw.started {
+		lw.w.WriteByte('\n')
+	}
+	for _, warning := range f.Warnings {
+		lw.writeLine("warning: %s", warning)
+	}
+	return lw.w.Flush()
+}
+
+// lineWriter separates lines with "\n" without a trailing newline.
+type lineWriter struct {
+	w       *bufio.Writer
+	started bool
+}
+
+func (lw *lineWriter) writeLine(format string, args ...interface{}) {
+	if lw.started {
+		lw.w.WriteByte('\n')
+	}
+	lw.started = true
+	fmt.Fprintf(lw.w, format, args...)
+}
+
+func (f *PlainFormatter) writePlainLines(lw *lineWriter, nodes []*diff.Node, parentPath string) {
+	for _, node := range nodes {
+		currentPath := buildPath(parentPath, node.Key)
+
+		switch node.Type {
+		case diff.NodeTypeAdded:
+			lw.writeLine(
+				"Property '%s' was added with value: %s",
+				currentPath,
+				formatPlainValue(node.Value),
+			)
+
+		case diff.NodeTypeRemoved:
+			lw.writeLine(
+				"Property '%s' was removed",
+				currentPath,
+			)
+
+		case diff.NodeTypeChanged:
+			if oldText, newText, multiline, ok := textChange(node, f.WordDiffLen); ok {
+				if multiline {
+					n := textdiff.ChangedLines(textdiff.Lines(oldText, newText))
+					lw.writeLine("Property '%s' changed %d %s", currentPath, n, plural(n, "line"))
+				} else {
+					n := changedWords(textdiff.Words(oldText, newText))
+					lw.writeLine("Property '%s' changed %d %s", currentPath, n, plural(n, "word"))
+				}
+				continue
+			}
+			lw.writeLine(
+				"Property '%s' was updated. From %s to %s%s",
+				currentPath,
+				formatPlainValue(node.OldValue),
+				formatPlainValue(node.NewValue),
+				formatAnnotation(node.Annotation),
+			)
+
+		case diff.NodeTypeTypeChanged:
+			lw.writeLine(
+				"Property '%s' changed type from %s %s to %s %s",
+				currentPath,
+				node.OldType,
+				formatPlainValue(node.OldValue),
+				node.NewType,
+				formatPlainValue(node.NewValue),
+			)
+
+		case diff.NodeTypeMoved, diff.NodeTypeRenamed:
+			lw.writeLine(
+				"Property '%s' was %s from '%s'",
+				currentPath,
+				node.Type,
+				node.From,
+			)
+			f.writePlainLines(lw, node.Children, currentPath)
+
+		case diff.NodeTypeUnchanged:
+			// Unchanged properties are not rendered in the plain format
+			continue
+
+		case diff.NodeTypeNested:
+			// Process nested nodes recursively
+			f.writePlainLines(lw, node.Children, currentPath)
+		}
+	}
+}
+
+func formatAnnotation(note string) string {
+	if note == "" {
+		return ""
+	}
+	return " (" + note + ")"
+}
+
+func buildPath(parentPath, key string) string {
+	if parentPath == "" {
+		return key
+	}
+	return parentPath + "." + key
+}
+
+func formatPlainValue(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+
+	// If the value is a map, output [complex value]
+	if _, ok := v.(map[string]interface{}); ok {
+		return "[complex value]"
+	}
+
+	// Arrays and slices are also considered complex values
+	if _, ok := v.([]interface{}); ok {
+		return "[complex value]"
+	}
+
+	switch val := v.(type) {
+	case string:
+		// Wrap strings in single quotes
+		return fmt.Sprintf("'%s'", val)
+	case bool:
+		if val {
+			return "true"
+		}
+		return "false"
+	case float64:
+		if val == float64(int64(val)) {
+			return fmt.Sprintf("%.0f", val)
+		}
+		return fmt.Sprintf("%g", val)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
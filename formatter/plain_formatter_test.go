@@ -3,7 +3,7 @@ package formatter
 import (
 	"testing"
 
-	"code/internal/diff"
+	"code/diff"
 
 	"github.com/stretchr/testify/require"
 )
@@ -75,6 +75,27 @@ func TestPlainFormatter_Format(t *testing.T) {
 				"Property 'key3' was updated. From 'old' to 'new'",
 			},
 		},
+		{
+			name: "multi-line text",
+			nodes: []*diff.Node{
+				{Type: diff.NodeTypeChanged, Key: "script", OldValue: "a\nb\nc", NewValue: "a\nB\nc\nd"},
+			},
+			expectExact: "Property 'script' changed 2 lines",
+		},
+		{
+			name: "annotated",
+			nodes: []*diff.Node{
+				{Type: diff.NodeTypeChanged, Key: "timeout", OldValue: "1m", NewValue: "90s", Annotation: "increased by 50%"},
+			},
+			expectExact: "Property 'timeout' was updated. From '1m' to '90s' (increased by 50%)",
+		},
+		{
+			name: "type changed",
+			nodes: []*diff.Node{
+				{Type: diff.NodeTypeTypeChanged, Key: "port", OldValue: "80", NewValue: 80.0, OldType: "string", NewType: "number"},
+			},
+			expectExact: "Property 'port' changed type from string '80' to number 80",
+		},
 		{
 			name: "nested and complex values",
 			nodes: []*diff.Node{
@@ -109,6 +130,25 @@ func TestPlainFormatter_Format(t *testing.T) {
 			},
 			expectNotContains: []string{"ignored"},
 		},
+		{
+			name: "moved and renamed",
+			nodes: []*diff.Node{
+				{Type: diff.NodeTypeMoved, Key: "db", From: "db", Value: map[string]interface{}{"a": 1.0}},
+				{
+					Type: diff.NodeTypeRenamed,
+					Key:  "caching",
+					From: "cache",
+					Children: []*diff.Node{
+						{Type: diff.NodeTypeChanged, Key: "ttl", OldValue: 60.0, NewValue: 120.0},
+					},
+				},
+			},
+			expectContains: []string{
+				"Property 'db' was moved from 'db'",
+				"Property 'caching' was renamed from 'cache'",
+				"Property 'caching.ttl' was updated. From 60 to 120",
+			},
+		},
 		{
 			name:        "empty nodes",
 			expectExact: "",
@@ -137,3 +177,18 @@ func TestPlainFormatter_Format(t *testing.T) {
 		})
 	}
 }
+
+func TestPlainFormatter_Warnings(t *testing.T) {
+	warnings := []diff.Warning{{Source: "a.yml", Path: "mode", Message: "value is not one of the allowed values"}}
+
+	result, err := (&PlainFormatter{Warnings: warnings}).Format([]*diff.Node{
+		{Type: diff.NodeTypeAdded, Key: "mode", Value: "slow"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Property 'mode' was added with value: 'slow'\n\n"+
+		"warning: a.yml: mode: value is not one of the allowed values", result)
+
+	result, err = (&PlainFormatter{Warnings: warnings}).Format(nil)
+	require.NoError(t, err)
+	require.Equal(t, "warning: a.yml: mode: value is not one of the allowed values", result)
+}
@@ -0,0 +1,287 @@
+package formatter
+
+import (
+	"bufio"
+	"code/diff"
+	"code/internal/textdiff"
+	"code/internal/utils"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const indentSize = 4
+
+// StylishFormatter renders the diff as an indented tree. With Collapse set,
+// only Context unchanged keys are printed next to each change; the others
+// are replaced by "... N unchanged keys ..." markers. QuoteStrings quotes
+// strings that could be mistaken for other values, such as "" or "null".
+// Changed multi-line strings are shown as line diffs, and single-line ones
+// of at least WordDiffLen characters as word diffs when it is positive.
+// Warnings, when present, follow the tree after a blank line.
+type StylishFormatter struct {
+	Collapse     bool
+	Context      int
+	QuoteStrings bool
+	WordDiffLen  int
+	Warnings     []diff.Warning
+}
+
+func (f *StylishFormatter) Format(nodes []*diff.Node) (string, error) {
+	var sb strings.Builder
+	if err := f.FormatTo(&sb, nodes); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func (f *StylishFormatter) FormatTo(w io.Writer, nodes []*diff.Node) error {
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString("{\n")
+	f.formatNodes(bw, nodes, 1)
+	bw.WriteString("}")
+	if len(f.Warnings) > 0 {
+		bw.WriteString("\n")
+	}
+	for _, warning := range f.Warnings {
+		fmt.Fprintf(bw, "\nwarning: %s", warning)
+	}
+
+	return bw.Flush()
+}
+
+func (f *StylishFormatter) formatNodes(w io.Writer, nodes []*diff.Node, depth int) {
+	visible := f.visibleNodes(nodes)
+	hidden := 0
+
+	for i, node := range nodes {
+		if !visible[i] {
+			hidden++
+			continue
+		}
+		writeHidden(w, depth, hidden)
+		hidden = 0
+
+		switch node.Type {
+		case diff.NodeTypeAdded:
+			f.formatValue(w, depth, "+", node.Key, node.Value)
+
+		case diff.NodeTypeRemoved:
+			f.formatValue(w, depth, "-", node.Key, node.Value)
+
+		case diff.NodeTypeChanged:
+			if oldText, newText, multiline, ok := textChange(node, f.WordDiffLen); ok {
+				formatText(w, depth, node.Key, oldText, newText, multiline)
+				continue
+			}
+			f.formatValue(w, depth, "-", node.Key, node.OldValue)
+			if node.Annotation != "" {
+				fmt.Fprintf(w, "%s%s: %s (%s)\n", makeIndent(depth, "+"), node.Key, f.simpleValue(node.NewValue), node.Annotation)
+			} else {
+				f.formatValue(w, depth, "+", node.Key, node.NewValue)
+			}
+
+		case diff.NodeTypeTypeChanged:
+			f.formatValue(w, depth, "-", typedKey(node.Key, node.OldType), node.OldValue)
+			f.formatValue(w, depth, "+", typedKey(node.Key, node.NewType), node.NewValue)
+
+		case diff.NodeTypeUnchanged:
+			f.formatValue(w, depth, " ", node.Key, node.Value)
+
+		case diff.NodeTypeNested:
+			f.formatChildren(w, depth, " ", node.Key, node.Children)
+
+		case diff.NodeTypeMoved, diff.NodeTypeRenamed:
+			key := fmt.Sprintf("%s (%s from %s)", node.Key, node.Type, node.From)
+			if hasChildren(node) {
+				f.formatChildren(w, depth, ">", key, node.Children)
+			} else {
+				f.formatValue(w, depth, ">", key, node.Value)
+			}
+		}
+	}
+	writeHidden(w, depth, hidden)
+}
+
+// visibleNodes marks the nodes to print: all of them unless collapsing,
+// otherwise changes and the unchanged nodes within Context of a change.
+func (f *StylishFormatter) visibleNodes(nodes []*diff.Node) []bool {
+	visible := make([]bool, len(nodes))
+	for i, node := range nodes {
+		if !f.Collapse {
+			visible[i] = true
+			continue
+		}
+		if !hasChanges(node) {
+			continue
+		}
+		for j := max(i-f.Context, 0); j <= min(i+f.Context, len(nodes)-1); j++ {
+			visible[j] = true
+		}
+	}
+	return visible
+}
+
+// hasChanges reports whether a node or anything below it changed.
+func hasChanges(node *diff.Node) bool {
+	switch node.Type {
+	case diff.NodeTypeUnchanged:
+		return false
+	case diff.NodeTypeNested:
+		for _, child := range node.Children {
+			if hasChanges(child) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func writeHidden(w io.Writer, depth, hidden int) {
+	if hidden > 0 {
+		fmt.Fprintf(w, "%s... %d unchanged %s ...\n", makeIndentForMap(depth), hidden, plural(hidden, "key"))
+	}
+}
+
+func (f *StylishFormatter) formatChildren(w io.Writer, depth int, marker, key string, children []*diff.Node) {
+	fmt.Fprintf(w, "%s%s: {\n", makeIndent(depth, marker), key)
+
+	f.formatNodes(w, children, depth+1)
+
+	fmt.Fprintf(w, "%s}\n", makeIndent(depth, " "))
+}
+
+func (f *StylishFormatter) formatValue(w io.Writer, depth int, marker string, key string, value interface{}) {
+	f.writeEntry(w, makeIndent(depth, marker)+key+": ", value, depth)
+}
+
+// writeEntry writes prefix followed by value. Objects and arrays open a
+// block whose contents are indented one level deeper than depth.
+func (f *StylishFormatter) writeEntry(w io.Writer, prefix string, value interface{}, depth int) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		fmt.Fprintf(w, "%s{\n", prefix)
+		f.formatMap(w, v, depth+1)
+		fmt.Fprintf(w, "%s}\n", makeIndentForMap(depth))
+
+	case []interface{}:
+		if len(v) == 0 {
+			fmt.Fprintf(w, "%s[]\n", prefix)
+			return
+		}
+		fmt.Fprintf(w, "%s[\n", prefix)
+		for _, item := range v {
+			f.writeEntry(w, makeIndentForMap(depth+1), item, depth+1)
+		}
+		fmt.Fprintf(w, "%s]\n", makeIndentForMap(depth))
+
+	default:
+		fmt.Fprintf(w, "%s%s\n", prefix, f.simpleValue(value))
+	}
+}
+
+// formatText renders a changed string as a unified diff of its lines, or as
+// an inline word diff for long single-line strings.
+func formatText(w io.Writer, depth int, key, oldText, newText string, multiline bool) {
+	if !multiline {
+		fmt.Fprintf(w, "%s%s: %s\n", makeIndent(depth, "~"), key, inlineWords(textdiff.Words(oldText, newText)))
+		return
+	}
+
+	fmt.Fprintf(w, "%s%s: |\n", makeIndent(depth, "~"), key)
+
+	for _, hunk := range textdiff.Hunks(textdiff.Lines(oldText, newText), textContext) {
+		fmt.Fprintf(w, "%s@@ -%d,%d +%d,%d @@\n", makeIndentForMap(depth+1),
+			hunk.OldStart, hunk.OldLines, hunk.NewStart, hunk.NewLines)
+
+		for _, op := range hunk.Ops {
+			marker := " "
+			switch op.Kind {
+			case textdiff.Delete:
+				marker = "-"
+			case textdiff.Insert:
+				marker = "+"
+			}
+			line := makeIndent(depth+1, marker) + op.Text
+			if op.Text == "" {
+				line = strings.TrimRight(line, " ")
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+}
+
+// typedKey annotates a key with its value type, e.g. "port (string)".
+func typedKey(key, typeName string) string {
+	return fmt.Sprintf("%s (%s)", key, typeName)
+}
+
+func (f *StylishFormatter) formatMap(w io.Writer, m map[string]interface{}, depth int) {
+	for _, k := range utils.SortedKeys(m) {
+		f.writeEntry(w, makeIndentForMap(depth)+k+": ", m[k], depth)
+	}
+}
+
+// simpleValue is formatSimpleValue with ambiguous strings quoted when
+// QuoteStrings is set.
+func (f *StylishFormatter) simpleValue(v interface{}) string {
+	if s, ok := v.(string); ok && f.QuoteStrings && isAmbiguous(s) {
+		return strconv.Quote(s)
+	}
+	return formatSimpleValue(v)
+}
+
+func formatSimpleValue(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+
+	switch val := v.(type) {
+	case string:
+		return val
+	case bool:
+		if val {
+			return "true"
+		}
+		return "false"
+	case float64:
+		if val == float64(int64(val)) {
+			return fmt.Sprintf("%.0f", val)
+		}
+		return fmt.Sprintf("%g", val)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// isAmbiguous reports whether a string printed bare could be mistaken for
+// another value: empty or padded strings, null, booleans, numbers, or text
+// spanning several lines.
+func isAmbiguous(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s || strings.ContainsAny(s, "\n\r") {
+		return true
+	}
+	switch s {
+	case "null", "true", "false":
+		return true
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+func makeIndent(depth int, marker string) string {
+	spacesCount := depth*indentSize - 2
+	if spacesCount < 0 {
+		spacesCount = 0
+	}
+	return strings.Repeat(" ", spacesCount) + marker + " "
+}
+
+func makeIndentForMap(depth int) string {
+	spacesCount := depth * indentSize
+	return strings.Repeat(" ", spacesCount)
+}
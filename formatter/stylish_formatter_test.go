@@ -0,0 +1,318 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"code/diff"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStylishFormatter_SimpleValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		quote    bool
+		expected string
+	}{
+		{name: "nil", value: nil, expected: "null"},
+		{name: "string", value: "text", expected: "text"},
+		{name: "string with inner spaces", value: "so much", quote: true, expected: "so much"},
+		{name: "empty string", value: "", expected: ""},
+		{name: "quoted empty string", value: "", quote: true, expected: `""`},
+		{name: "padded string", value: " text", quote: true, expected: `" text"`},
+		{name: "null string", value: "null", quote: true, expected: `"null"`},
+		{name: "bool string", value: "true", quote: true, expected: `"true"`},
+		{name: "number string", value: "8080", quote: true, expected: `"8080"`},
+		{name: "unquoted number string", value: "8080", expected: "8080"},
+		{name: "multi-line string", value: "a\nb", quote: true, expected: `"a\nb"`},
+		{name: "bool true", value: true, expected: "true"},
+		{name: "bool false", value: false, quote: true, expected: "false"},
+		{name: "int float64", value: float64(10), expected: "10"},
+		{name: "fraction float64", value: 3.14, expected: "3.14"},
+		{name: "default", value: []int{1, 2}, expected: "[1 2]"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result := (&StylishFormatter{QuoteStrings: tt.quote}).simpleValue(tt.value)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestMakeIndent(t *testing.T) {
+	tests := []struct {
+		name     string
+		depth    int
+		marker   string
+		expected string
+	}{
+		{name: "depth one plus", depth: 1, marker: "+", expected: "  + "},
+		{name: "zero depth space", depth: 0, marker: " ", expected: "  "},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, makeIndent(tt.depth, tt.marker))
+		})
+	}
+}
+
+func TestMakeIndentForMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		depth    int
+		expected string
+	}{
+		{name: "depth zero", depth: 0, expected: ""},
+		{name: "depth three", depth: 3, expected: "            "},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, makeIndentForMap(tt.depth))
+		})
+	}
+}
+
+func TestStylishFormatter_Format(t *testing.T) {
+	tests := []struct {
+		name           string
+		nodes          []*diff.Node
+		expectContains []string
+		expectExact    string
+	}{
+		{
+			name: "non empty",
+			nodes: []*diff.Node{
+				{Type: diff.NodeTypeAdded, Key: "key1", Value: "value1"},
+				{Type: diff.NodeTypeRemoved, Key: "key2", Value: 42.0},
+				{Type: diff.NodeTypeUnchanged, Key: "key3", Value: true},
+			},
+			expectContains: []string{"+ key1: value1", "- key2: 42", "  key3: true"},
+		},
+		{
+			name: "annotated",
+			nodes: []*diff.Node{
+				{Type: diff.NodeTypeChanged, Key: "timeout", OldValue: "1m", NewValue: "90s", Annotation: "increased by 50%"},
+			},
+			expectExact: "{\n  - timeout: 1m\n  + timeout: 90s (increased by 50%)\n}",
+		},
+		{
+			name: "type changed",
+			nodes: []*diff.Node{
+				{Type: diff.NodeTypeTypeChanged, Key: "port", OldValue: "80", NewValue: 80.0, OldType: "string", NewType: "number"},
+			},
+			expectExact: "{\n  - port (string): 80\n  + port (number): 80\n}",
+		},
+		{
+			name:        "empty nodes",
+			expectExact: "{\n}",
+		},
+		{
+			name: "moved and renamed",
+			nodes: []*diff.Node{
+				{Type: diff.NodeTypeMoved, Key: "db", From: "old.db", Value: "x"},
+				{
+					Type: diff.NodeTypeRenamed,
+					Key:  "caching",
+					From: "cache",
+					Children: []*diff.Node{
+						{Type: diff.NodeTypeUnchanged, Key: "host", Value: "redis"},
+					},
+				},
+			},
+			expectExact: strings.Join([]string{
+				"{",
+				"  > db (moved from old.db): x",
+				"  > caching (renamed from cache): {",
+				"        host: redis",
+				"    }",
+				"}",
+			}, "\n"),
+		},
+		{
+			name: "complex nested",
+			nodes: []*diff.Node{
+				{
+					Type: diff.NodeTypeAdded,
+					Key:  "object",
+					Value: map[string]interface{}{
+						"inner":  map[string]interface{}{"foo": "bar"},
+						"number": float64(3),
+					},
+				},
+				{
+					Type:     diff.NodeTypeChanged,
+					Key:      "count",
+					OldValue: float64(1),
+					NewValue: 2.0,
+				},
+				{
+					Type: diff.NodeTypeNested,
+					Key:  "group",
+					Children: []*diff.Node{
+						{Type: diff.NodeTypeRemoved, Key: "old", Value: false},
+						{Type: diff.NodeTypeAdded, Key: "new", Value: "value"},
+					},
+				},
+			},
+			expectExact: strings.Join([]string{
+				"{",
+				"  + object: {",
+				"        inner: {",
+				"            foo: bar",
+				"        }",
+				"        number: 3",
+				"    }",
+				"  - count: 1",
+				"  + count: 2",
+				"    group: {",
+				"      - old: false",
+				"      + new: value",
+				"    }",
+				"}",
+			}, "\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			f := &StylishFormatter{}
+			result, err := f.Format(tt.nodes)
+
+			require.NoError(t, err)
+			if tt.expectExact != "" {
+				require.Equal(t, tt.expectExact, result)
+				return
+			}
+
+			for _, substr := range tt.expectContains {
+				require.Contains(t, result, substr)
+			}
+		})
+	}
+}
+
+func TestStylishFormatter_Warnings(t *testing.T) {
+	f := &StylishFormatter{Warnings: []diff.Warning{
+		{Source: "a.yml", Path: "replicas", Message: "value is less than minimum 1"},
+		{Source: "b.json", Line: 4, Path: "port", Message: "duplicate key"},
+	}}
+	result, err := f.Format([]*diff.Node{{Type: diff.NodeTypeChanged, Key: "replicas", OldValue: 1.0, NewValue: 0.0}})
+	require.NoError(t, err)
+	require.Equal(t, strings.Join([]string{
+		"{",
+		"  - replicas: 1",
+		"  + replicas: 0",
+		"}",
+		"",
+		"warning: a.yml: replicas: value is less than minimum 1",
+		"warning: b.json:4: port: duplicate key",
+	}, "\n"), result)
+}
+
+func TestStylishFormatter_Collapse(t *testing.T) {
+	nodes := []*diff.Node{
+		{Type: diff.NodeTypeUnchanged, Key: "a", Value: 1.0},
+		{Type: diff.NodeTypeUnchanged, Key: "b", Value: 2.0},
+		{Type: diff.NodeTypeUnchanged, Key: "c", Value: map[string]interface{}{"x": 1.0}},
+		{Type: diff.NodeTypeChanged, Key: "d", OldValue: 1.0, NewValue: 2.0},
+		{Type: diff.NodeTypeUnchanged, Key: "e", Value: 5.0},
+		{
+			Type: diff.NodeTypeNested,
+			Key:  "f",
+			Children: []*diff.Node{
+				{Type: diff.NodeTypeUnchanged, Key: "g", Value: 1.0},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		context  int
+		expected []string
+	}{
+		{
+			name:    "no context",
+			context: 0,
+			expected: []string{
+				"{",
+				"    ... 3 unchanged keys ...",
+				"  - d: 1",
+				"  + d: 2",
+				"    ... 2 unchanged keys ...",
+				"}",
+			},
+		},
+		{
+			name:    "one key of context",
+			context: 1,
+			expected: []string{
+				"{",
+				"    ... 2 unchanged keys ...",
+				"    c: {",
+				"        x: 1",
+				"    }",
+				"  - d: 1",
+				"  + d: 2",
+				"    e: 5",
+				"    ... 1 unchanged key ...",
+				"}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			f := &StylishFormatter{Collapse: true, Context: tt.context}
+			result, err := f.Format(nodes)
+			require.NoError(t, err)
+			require.Equal(t, strings.Join(tt.expected, "\n"), result)
+		})
+	}
+}
+
+func TestStylishFormatter_Arrays(t *testing.T) {
+	nodes := []*diff.Node{
+		{Type: diff.NodeTypeAdded, Key: "empty", Value: []interface{}{}},
+		{
+			Type: diff.NodeTypeUnchanged,
+			Key:  "items",
+			Value: []interface{}{
+				1.0,
+				"",
+				map[string]interface{}{"name": "a", "tags": []interface{}{"x"}},
+				[]interface{}{true},
+			},
+		},
+	}
+
+	result, err := (&StylishFormatter{QuoteStrings: true}).Format(nodes)
+	require.NoError(t, err)
+	require.Equal(t, strings.Join([]string{
+		"{",
+		"  + empty: []",
+		"    items: [",
+		"        1",
+		`        ""`,
+		"        {",
+		"            name: a",
+		"            tags: [",
+		"                x",
+		"            ]",
+		"        }",
+		"        [",
+		"            true",
+		"        ]",
+		"    ]",
+		"}",
+	}, "\n"), result)
+}
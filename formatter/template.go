@@ -0,0 +1,106 @@
+package formatter
+
+import (
+	"bufio"
+	"code/diff"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var ErrTemplateRequired = errors.New("template path is required")
+
+// TemplateFormatter renders the diff tree through a user-supplied text/template.
+//
+// The template receives a templateScope with the root nodes and an empty path.
+// Available helpers:
+//
+//	path       joins a parent path and a key: {{path .Path .Key}}
+//	plainValue renders a value like the plain format: 'text', [complex value]
+//	value      renders a value like the stylish format
+//	walk       flattens nodes depth-first into entries with Path, Depth and Node
+//	nest       builds a scope for recursion: {{template "nodes" (nest .Children (path $.Path .Key))}}
+type TemplateFormatter struct {
+	tmpl *template.Template
+}
+
+type templateScope struct {
+	Path  string
+	Nodes []*diff.Node
+}
+
+type templateEntry struct {
+	Path  string
+	Depth int
+	Node  *diff.Node
+}
+
+// NewTemplateFormatter parses the template file at path.
+func NewTemplateFormatter(path string) (*TemplateFormatter, error) {
+	if path == "" {
+		return nil, ErrTemplateRequired
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read template %q: %w", path, err)
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs()).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse template %q: %w", path, err)
+	}
+
+	return &TemplateFormatter{tmpl: tmpl}, nil
+}
+
+func (f *TemplateFormatter) Format(nodes []*diff.Node) (string, error) {
+	var sb strings.Builder
+	if err := f.FormatTo(&sb, nodes); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func (f *TemplateFormatter) FormatTo(w io.Writer, nodes []*diff.Node) error {
+	bw := bufio.NewWriter(w)
+	if err := f.tmpl.Execute(bw, nest(nodes, "")); err != nil {
+		return fmt.Errorf("execute template: %w", err)
+	}
+	return bw.Flush()
+}
+
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"path":       buildPath,
+		"plainValue": formatPlainValue,
+		"value":      formatSimpleValue,
+		"walk":       walk,
+		"nest":       nest,
+	}
+}
+
+func nest(nodes []*diff.Node, parentPath string) templateScope {
+	return templateScope{Path: parentPath, Nodes: nodes}
+}
+
+func walk(nodes []*diff.Node) []templateEntry {
+	var entries []templateEntry
+	collectEntries(nodes, "", 0, &entries)
+	return entries
+}
+
+func collectEntries(nodes []*diff.Node, parentPath string, depth int, entries *[]templateEntry) {
+	for _, node := range nodes {
+		currentPath := buildPath(parentPath, node.Key)
+		*entries = append(*entries, templateEntry{Path: currentPath, Depth: depth, Node: node})
+
+		if node.Type == diff.NodeTypeNested {
+			collectEntries(node.Children, currentPath, depth+1, entries)
+		}
+	}
+}
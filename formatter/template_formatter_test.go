@@ -0,0 +1,127 @@
+package formatter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code/diff"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeTemplate(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "diff.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestNewTemplateFormatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        func(t *testing.T) string
+		errIs       error
+		errContains string
+	}{
+		{
+			name:  "empty path",
+			path:  func(t *testing.T) string { return "" },
+			errIs: ErrTemplateRequired,
+		},
+		{
+			name:        "missing file",
+			path:        func(t *testing.T) string { return filepath.Join(t.TempDir(), "none.tmpl") },
+			errContains: "read template",
+		},
+		{
+			name:        "invalid template",
+			path:        func(t *testing.T) string { return writeTemplate(t, "{{range}}") },
+			errContains: "parse template",
+		},
+		{
+			name: "valid template",
+			path: func(t *testing.T) string { return writeTemplate(t, "{{len .Nodes}}") },
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewTemplateFormatter(tt.path(t))
+			if tt.errIs != nil || tt.errContains != "" {
+				require.Error(t, err)
+				require.Nil(t, f)
+				if tt.errIs != nil {
+					require.ErrorIs(t, err, tt.errIs)
+				}
+				require.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, f)
+		})
+	}
+}
+
+func TestTemplateFormatter_Format(t *testing.T) {
+	nodes := []*diff.Node{
+		{Type: diff.NodeTypeAdded, Key: "key1", Value: "value1"},
+		{
+			Type: diff.NodeTypeNested,
+			Key:  "parent",
+			Children: []*diff.Node{
+				{Type: diff.NodeTypeChanged, Key: "child", OldValue: float64(1), NewValue: map[string]interface{}{"a": 1.0}},
+				{Type: diff.NodeTypeUnchanged, Key: "same", Value: true},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		template    string
+		expected    string
+		errContains string
+	}{
+		{
+			name: "walk",
+			template: `{{range walk .Nodes}}{{if ne .Node.Type "nested"}}` +
+				`{{.Depth}} {{.Node.Type}} {{.Path}}{{"\n"}}{{end}}{{end}}`,
+			expected: "0 added key1\n1 changed parent.child\n1 unchanged parent.same\n",
+		},
+		{
+			name: "recursion with nest",
+			template: `{{define "nodes"}}{{range .Nodes}}` +
+				`{{if eq .Type "nested"}}{{template "nodes" (nest .Children (path $.Path .Key))}}` +
+				`{{else if eq .Type "added"}}{{path $.Path .Key}} = {{plainValue .Value}}{{"\n"}}` +
+				`{{else if eq .Type "changed"}}{{path $.Path .Key}}: {{value .OldValue}} -> {{plainValue .NewValue}}{{"\n"}}` +
+				`{{end}}{{end}}{{end}}{{template "nodes" .}}`,
+			expected: "key1 = 'value1'\nparent.child: 1 -> [complex value]\n",
+		},
+		{
+			name:        "execution error",
+			template:    `{{index .Nodes 10}}`,
+			errContains: "execute template",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewTemplateFormatter(writeTemplate(t, tt.template))
+			require.NoError(t, err)
+
+			result, err := f.Format(nodes)
+			if tt.errContains != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package formatter
+
+import (
+	"code/diff"
+	"code/internal/textdiff"
+	"strings"
+	"unicode/utf8"
+)
+
+// textContext is the number of unchanged lines kept around changes.
+const textContext = 3
+
+// textChange returns both sides of a changed node whose values are
+// multi-line strings or, when wordDiffLen is positive, single-line strings
+// of at least wordDiffLen characters. Annotated changes, and changes the
+// line or word diff cannot show, keep their regular rendering.
+func textChange(node *diff.Node, wordDiffLen int) (oldText, newText string, multiline, ok bool) {
+	if node.Type != diff.NodeTypeChanged || node.Annotation != "" {
+		return "", "", false, false
+	}
+
+	oldText, ok1 := node.OldValue.(string)
+	newText, ok2 := node.NewValue.(string)
+	if !ok1 || !ok2 {
+		return "", "", false, false
+	}
+
+	if textdiff.IsMultiline(oldText) || textdiff.IsMultiline(newText) {
+		if !textdiff.Changed(textdiff.Lines(oldText, newText)) {
+			return "", "", false, false
+		}
+		return oldText, newText, true, true
+	}
+
+	if wordDiffLen > 0 && (utf8.RuneCountInString(oldText) >= wordDiffLen || utf8.RuneCountInString(newText) >= wordDiffLen) {
+		if !textdiff.Changed(textdiff.Words(oldText, newText)) {
+			return "", "", false, false
+		}
+		return oldText, newText, false, true
+	}
+
+	return "", "", false, false
+}
+
+// changedWords counts changed words, ignoring whitespace tokens.
+func changedWords(ops []textdiff.Op) int {
+	words := make([]textdiff.Op, 0, len(ops))
+	for _, op := range ops {
+		if strings.TrimSpace(op.Text) != "" {
+			words = append(words, op)
+		}
+	}
+	return textdiff.ChangedLines(words)
+}
+
+// inlineWords renders a word diff as "kept [-old-]{+new+} kept".
+func inlineWords(ops []textdiff.Op) string {
+	var sb strings.Builder
+	for _, op := range ops {
+		switch op.Kind {
+		case textdiff.Delete:
+			sb.WriteString("[-" + op.Text + "-]")
+		case textdiff.Insert:
+			sb.WriteString("{+" + op.Text + "+}")
+		default:
+			sb.WriteString(op.Text)
+		}
+	}
+	return sb.String()
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
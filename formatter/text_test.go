@@ -0,0 +1,158 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+
+	"code/diff"
+	"code/internal/textdiff"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextChange(t *testing.T) {
+	long := strings.Repeat("word ", 20)
+
+	tests := []struct {
+		name        string
+		node        *diff.Node
+		wordDiffLen int
+		ok          bool
+		multiline   bool
+	}{
+		{
+			name: "short strings",
+			node: &diff.Node{Type: diff.NodeTypeChanged, OldValue: "a", NewValue: "b"},
+		},
+		{
+			name:      "multi-line",
+			node:      &diff.Node{Type: diff.NodeTypeChanged, OldValue: "a\nb", NewValue: "a"},
+			ok:        true,
+			multiline: true,
+		},
+		{
+			name:        "long single line",
+			node:        &diff.Node{Type: diff.NodeTypeChanged, OldValue: long, NewValue: long + "!"},
+			wordDiffLen: 80,
+			ok:          true,
+		},
+		{
+			name: "long single line without word diff",
+			node: &diff.Node{Type: diff.NodeTypeChanged, OldValue: long, NewValue: long + "!"},
+		},
+		{
+			name:        "single line below word diff length",
+			node:        &diff.Node{Type: diff.NodeTypeChanged, OldValue: "a b", NewValue: "a c"},
+			wordDiffLen: 80,
+		},
+		{
+			name: "not a string",
+			node: &diff.Node{Type: diff.NodeTypeChanged, OldValue: "a\nb", NewValue: 1.0},
+		},
+		{
+			name: "annotated",
+			node: &diff.Node{Type: diff.NodeTypeChanged, OldValue: "a\nb", NewValue: "a", Annotation: "note"},
+		},
+		{
+			name: "added",
+			node: &diff.Node{Type: diff.NodeTypeAdded, Value: "a\nb"},
+		},
+		{
+			name:      "final newline only",
+			node:      &diff.Node{Type: diff.NodeTypeChanged, OldValue: "a\nb\n", NewValue: "a\nb"},
+			ok:        true,
+			multiline: true,
+		},
+		{
+			name: "lines that print alike",
+			node: &diff.Node{Type: diff.NodeTypeChanged, OldValue: "a\r\nb", NewValue: `a\r` + "\nb"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, multiline, ok := textChange(tt.node, tt.wordDiffLen)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.multiline, multiline)
+		})
+	}
+}
+
+func TestInlineWords(t *testing.T) {
+	ops := textdiff.Words("listen on port 80", "listen on port 443 now")
+	require.Equal(t, "listen on port [-80-]{+443+}{+ +}{+now+}", inlineWords(ops))
+	require.Equal(t, 2, changedWords(ops))
+}
+
+func TestFormatText(t *testing.T) {
+	var sb strings.Builder
+	formatText(&sb, 1, "script", "a\nb\n\nc\n", "a\nB\n\nc\n", true)
+
+	expected := "  ~ script: |\n" +
+		"        @@ -1,4 +1,4 @@\n" +
+		"        a\n" +
+		"      - b\n" +
+		"      + B\n" +
+		"\n" +
+		"        c\n"
+	require.Equal(t, expected, sb.String())
+}
+
+func TestFormatters_LineEndings(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     *diff.Node
+		stylish  string
+		plain    string
+		htmlPart string
+	}{
+		{
+			name: "final newline",
+			node: &diff.Node{Type: diff.NodeTypeChanged, Key: "s", OldValue: "a\nb\n", NewValue: "a\nb"},
+			stylish: "{\n" +
+				"  ~ s: |\n" +
+				"        @@ -1,2 +1,2 @@\n" +
+				"        a\n" +
+				"        b\n" +
+				"      + \\ No newline at end of file\n" +
+				"}",
+			plain:    "Property 's' changed 1 line",
+			htmlPart: "<ins>+ \\ No newline at end of file\n</ins>",
+		},
+		{
+			name: "CRLF to LF",
+			node: &diff.Node{Type: diff.NodeTypeChanged, Key: "x", OldValue: "x\r\ny\r\n", NewValue: "x\ny\n"},
+			stylish: "{\n" +
+				"  ~ x: |\n" +
+				"        @@ -1,2 +1,2 @@\n" +
+				"      - x\\r\n" +
+				"      - y\\r\n" +
+				"      + x\n" +
+				"      + y\n" +
+				"}",
+			plain:    "Property 'x' changed 2 lines",
+			htmlPart: "<del>- x\\r\n</del>",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := []*diff.Node{tt.node}
+
+			stylish, err := (&StylishFormatter{}).Format(nodes)
+			require.NoError(t, err)
+			require.Equal(t, tt.stylish, stylish)
+			require.NotContains(t, stylish, "\r")
+
+			plain, err := (&PlainFormatter{}).Format(nodes)
+			require.NoError(t, err)
+			require.Equal(t, tt.plain, plain)
+
+			html, err := (&HTMLFormatter{}).Format(nodes)
+			require.NoError(t, err)
+			require.Contains(t, html, tt.htmlPart)
+		})
+	}
+}
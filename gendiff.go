@@ -1,34 +1,90 @@
 package code
 
 import (
-	"code/internal/diff"
-	"code/internal/formatter"
-	"code/internal/parser"
+	"code/compare"
+	"code/diff"
+	"code/formatter"
 	"code/internal/utils"
+	"code/merge"
+	"code/parser"
+	"code/redact"
+	"code/resolve"
+	"code/schema"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"strings"
 )
 
 var (
 	ErrEmptyPath = errors.New("file path cannot be empty")
+	ErrNilReader = errors.New("reader cannot be nil")
+	ErrNotObject = errors.New("value is not an object")
 )
 
 type Differ struct {
-	fileParser *parser.FileParser
+	fileParser    *parser.FileParser
+	parsers       []parserOverride
+	formatters    *formatter.Registry
+	formatterOpts []formatter.Option
+
+	maxInputSize int64
+	maxDepth     int
+	maxNodes     int
+
+	typeChanges   bool
+	moveThreshold float64
+	comparer      *compare.Comparer
+	redactor      *redact.Redactor
+	redactRules   []redact.Rule
+	redactKey     []byte
+
+	schema    *schema.Schema
+	strict    bool
+	onWarning func(diff.Warning)
+
+	lookupEnv   resolve.LookupFunc
+	resolveRefs bool
+	merger      *merge.Merger
 }
 
 type Option func(*Differ)
 
+type parserOverride struct {
+	parser parser.Parser
+	exts   []string
+}
+
 func NewDiffer(opts ...Option) *Differ {
 	d := &Differ{
 		fileParser: defaultParsers(),
+		formatters: formatter.Default(),
 	}
 
 	for _, opt := range opts {
 		opt(d)
 	}
 
+	if len(d.parsers) > 0 {
+		fp := parser.NewFileParser()
+		fp.Extend(d.fileParser)
+		for _, o := range d.parsers {
+			fp.Add(o.parser, o.exts...)
+		}
+		d.fileParser = fp
+	}
+
+	if d.redactRules != nil {
+		if d.redactKey != nil {
+			d.redactor = redact.NewWithKey(d.redactKey, d.redactRules...)
+		} else {
+			d.redactor = redact.New(d.redactRules...)
+		}
+	}
+
 	return d
 }
 
@@ -38,52 +94,460 @@ func WithFileParser(fp *parser.FileParser) Option {
 	}
 }
 
+// WithParser registers an additional parser for this Differ only. It is
+// added to a copy of the FileParser, whatever the order of the options, so
+// one passed to WithFileParser is left unchanged.
+func WithParser(p parser.Parser, exts ...string) Option {
+	return func(d *Differ) {
+		d.parsers = append(d.parsers, parserOverride{parser: p, exts: exts})
+	}
+}
+
+// WithFormatterRegistry replaces the registry used to look up output formats.
+func WithFormatterRegistry(r *formatter.Registry) Option {
+	return func(d *Differ) {
+		d.formatters = r
+	}
+}
+
+// WithFormatterOptions passes options to the formatter selected in GetDiff,
+// e.g. formatter.WithTemplatePath for the template format.
+func WithFormatterOptions(opts ...formatter.Option) Option {
+	return func(d *Differ) {
+		d.formatterOpts = append(d.formatterOpts, opts...)
+	}
+}
+
+// WithTypeChanges reports values whose type changed (e.g. string to number,
+// object to scalar) as diff.NodeTypeTypeChanged instead of plain changes.
+// Changes from or to null are still reported as regular changes.
+func WithTypeChanges() Option {
+	return func(d *Differ) {
+		d.typeChanges = true
+	}
+}
+
+// WithComparison relaxes value equality for paths matching the rules, e.g.
+// float tolerance, numeric strings or semantic comparators such as
+// compare.Duration. Changes found by semantic comparators are annotated.
+// The last matching rule wins; paths matching no rule are compared exactly.
+func WithComparison(rules ...compare.Rule) Option {
+	return func(d *Differ) {
+		d.comparer = compare.New(rules...)
+	}
+}
+
+// WithRedaction replaces secret values with HMAC markers before the diff
+// reaches formatters or callers. Without rules redact.Defaults is used.
+// Markers use a random key per Differ unless WithRedactionKey is given.
+func WithRedaction(rules ...redact.Rule) Option {
+	return func(d *Differ) {
+		if len(rules) == 0 {
+			rules = redact.Defaults()
+		}
+		d.redactRules = rules
+	}
+}
+
+// WithRedactionKey sets the HMAC key of redaction markers, so that they can
+// be compared across runs. It has no effect without WithRedaction.
+func WithRedactionKey(key []byte) Option {
+	return func(d *Differ) {
+		d.redactKey = key
+	}
+}
+
+// WithSchema validates both inputs against s and fills in its defaults
+// before diffing, so omitted keys equal to their default are not reported.
+// Violations go to the handler set with WithWarningHandler.
+func WithSchema(s *schema.Schema) Option {
+	return func(d *Differ) {
+		d.schema = s
+	}
+}
+
+// WithStrict reports input that parsers accept but that is likely a mistake,
+// such as duplicate JSON keys or non-string YAML keys, as warnings with the
+// line they were found on.
+func WithStrict() Option {
+	return func(d *Differ) {
+		d.strict = true
+	}
+}
+
+// WithEnvExpansion replaces ${VAR} and ${VAR:-default} placeholders in the
+// string values of both inputs with variables from lookup, such as
+// os.LookupEnv or resolve.Vars. A variable that is not set and has no
+// default is an error.
+func WithEnvExpansion(lookup resolve.LookupFunc) Option {
+	return func(d *Differ) {
+		d.lookupEnv = lookup
+	}
+}
+
+// WithRefResolution replaces {"$ref": "file#/pointer"} objects in both
+// inputs with the values they point to. Referenced files are relative to
+// the referring one, must be inside its directory and are read with the
+// Differ's parsers; together they may not exceed WithMaxInputSize. Readers
+// and values have no directory, so their refs may only point into
+// themselves.
+func WithRefResolution() Option {
+	return func(d *Differ) {
+		d.resolveRefs = true
+	}
+}
+
+// WithListMerge sets how lists combine when WriteLayeredDiff merges layers.
+// Lists are replaced by default. It has no effect on single-file sides.
+func WithListMerge(rules ...merge.Rule) Option {
+	return func(d *Differ) {
+		d.merger = merge.New(rules...)
+	}
+}
+
+// WithWarningHandler receives problems found in the inputs that do not stop
+// the diff, such as schema violations. Without a handler they are dropped;
+// formatters that support it, such as json, include them in the output.
+func WithWarningHandler(fn func(diff.Warning)) Option {
+	return func(d *Differ) {
+		d.onWarning = fn
+	}
+}
+
 func defaultParsers() *parser.FileParser {
 	p := parser.NewFileParser()
 	p.Add(&parser.JSONParser{}, ".json")
+	p.Add(&parser.JSONParser{Lenient: true}, ".jsonc")
 	p.Add(&parser.YAMLParser{}, ".yaml", ".yml")
+	p.Add(&parser.XMLParser{}, ".xml")
+	p.Add(&parser.PropertiesParser{}, ".properties")
+	p.Add(&parser.HOCONParser{}, ".hocon")
+	p.Add(&parser.HCLParser{}, ".hcl", ".tf", ".tfvars")
+	p.Add(&parser.CSVParser{}, ".csv")
+	p.Add(&parser.CSVParser{Comma: '\t'}, ".tsv")
+	p.AddDecompressor(parser.Gzip, ".gz")
+	p.AddDecompressor(parser.Bzip2, ".bz2")
+	p.AddDecompressor(parser.Zstd, ".zst")
+	p.Extend(parser.Registered())
 	return p
 }
 
 // GenDiff compares two files and returns the diff in the requested format.
 func GenDiff(path1, path2, format string) (string, error) {
-	return NewDiffer().GetDiff(path1, path2, format)
+	return NewDiffer().GetDiff(context.Background(), path1, path2, format)
 }
 
-func (d *Differ) GetDiff(path1, path2, format string) (string, error) {
-	if path1 == "" {
-		return "", fmt.Errorf("first file: %w", ErrEmptyPath)
+func (d *Differ) GetDiff(ctx context.Context, path1, path2, format string) (string, error) {
+	var sb strings.Builder
+	if err := d.WriteDiff(ctx, &sb, path1, path2, format); err != nil {
+		return "", err
 	}
-	if path2 == "" {
-		return "", fmt.Errorf("second file: %w", ErrEmptyPath)
+	return sb.String(), nil
+}
+
+// WriteDiff compares two files and writes the diff in the requested format to w.
+// Formatters that support streaming write directly to w.
+func (d *Differ) WriteDiff(ctx context.Context, w io.Writer, path1, path2, format string) error {
+	return d.WriteLayeredDiff(ctx, w, []string{path1}, []string{path2}, format)
+}
+
+// GetLayeredDiff is WriteLayeredDiff returning the diff as a string.
+func (d *Differ) GetLayeredDiff(ctx context.Context, left, right []string, format string) (string, error) {
+	var sb strings.Builder
+	if err := d.WriteLayeredDiff(ctx, &sb, left, right, format); err != nil {
+		return "", err
 	}
+	return sb.String(), nil
+}
 
-	data1, err := d.fileParser.Parse(path1)
+// WriteLayeredDiff deep-merges the files of each side in order, e.g. a base
+// file and an environment override, and writes the diff of the results.
+// Lists are combined as set by WithListMerge.
+func (d *Differ) WriteLayeredDiff(ctx context.Context, w io.Writer, left, right []string, format string) error {
+	if !validPaths(left) {
+		return fmt.Errorf("first file: %w", ErrEmptyPath)
+	}
+	if !validPaths(right) {
+		return fmt.Errorf("second file: %w", ErrEmptyPath)
+	}
+
+	var warnings []diff.Warning
+	report := func(w diff.Warning) {
+		warnings = append(warnings, w)
+		d.warn(w)
+	}
+
+	data1, err := d.parseLayers(ctx, left, report)
+	if err != nil {
+		return fmt.Errorf("parse first file %w", err)
+	}
+
+	data2, err := d.parseLayers(ctx, right, report)
 	if err != nil {
-		return "", fmt.Errorf("parse first file %q: %w", path1, err)
+		return fmt.Errorf("parse second file %w", err)
 	}
 
-	data2, err := d.fileParser.Parse(path2)
+	fmtOpts := d.formatterOpts
+	if len(warnings) > 0 {
+		fmtOpts = append(fmtOpts[:len(fmtOpts):len(fmtOpts)], formatter.WithWarnings(warnings))
+	}
+	fmter, err := d.formatters.Get(format, fmtOpts...)
 	if err != nil {
-		return "", fmt.Errorf("parse second file %q: %w", path2, err)
+		return fmt.Errorf("get formatter: %w", err)
 	}
 
-	fmter, err := formatter.GetFormatter(format)
+	nodes, err := d.buildTree(ctx, data1, data2)
 	if err != nil {
-		return "", fmt.Errorf("get formatter: %w", err)
+		return err
+	}
+
+	if err := formatter.FormatTo(w, fmter, nodes); err != nil {
+		return fmt.Errorf("format diff: %w", err)
 	}
 
-	nodes := d.getNodes(data1, data2)
+	return nil
+}
 
-	result, err := fmter.Format(nodes)
+// DiffReaders parses both readers with the parsers registered for format1 and
+// format2 (e.g. "json", ".yml") and returns the diff tree.
+func (d *Differ) DiffReaders(ctx context.Context, r1, r2 io.Reader, format1, format2 string) ([]*diff.Node, error) {
+	if r1 == nil {
+		return nil, fmt.Errorf("first reader: %w", ErrNilReader)
+	}
+	if r2 == nil {
+		return nil, fmt.Errorf("second reader: %w", ErrNilReader)
+	}
+
+	data1, err := d.parseReader(ctx, r1, format1, "first reader", d.warn)
+	if err != nil {
+		return nil, fmt.Errorf("parse first reader: %w", err)
+	}
+
+	data2, err := d.parseReader(ctx, r2, format2, "second reader", d.warn)
 	if err != nil {
-		return "", fmt.Errorf("format diff: %w", err)
+		return nil, fmt.Errorf("parse second reader: %w", err)
 	}
 
-	return result, nil
+	return d.buildTree(ctx, data1, data2)
 }
 
-func (d *Differ) getNodes(data1, data2 map[string]interface{}) []*diff.Node {
+// DiffValues compares two in-memory values and returns the diff tree.
+// Values are converted through encoding/json, so structs are keyed by their
+// json tags; both must encode to a JSON object (or be nil).
+func (d *Differ) DiffValues(ctx context.Context, v1, v2 any) ([]*diff.Node, error) {
+	data1, err := d.valueObject(ctx, v1, "first value", d.warn)
+	if err != nil {
+		return nil, fmt.Errorf("first value: %w", err)
+	}
+
+	data2, err := d.valueObject(ctx, v2, "second value", d.warn)
+	if err != nil {
+		return nil, fmt.Errorf("second value: %w", err)
+	}
+
+	return d.buildTree(ctx, data1, data2)
+}
+
+func validPaths(paths []string) bool {
+	for _, path := range paths {
+		if path == "" {
+			return false
+		}
+	}
+	return len(paths) > 0
+}
+
+// parseLayers parses paths and merges them in order. Limits and the schema
+// apply to the merged document, since a single layer is often incomplete.
+// Errors are prefixed with the quoted path that caused them.
+func (d *Differ) parseLayers(ctx context.Context, paths []string, report func(diff.Warning)) (map[string]interface{}, error) {
+	if len(paths) == 1 {
+		data, err := d.parseFile(ctx, paths[0], report)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", paths[0], err)
+		}
+		return data, nil
+	}
+
+	layers := make([]map[string]interface{}, 0, len(paths))
+	for _, path := range paths {
+		data, err := d.loadFile(ctx, path, report)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", path, err)
+		}
+		layers = append(layers, data)
+	}
+
+	merger := d.merger
+	if merger == nil {
+		merger = merge.New()
+	}
+	return d.prepare(ctx, strings.Join(paths, "+"), merger.Merge(layers...), report)
+}
+
+// parseFile, parseReader and valueObject send the warnings about their input
+// to report.
+func (d *Differ) parseFile(ctx context.Context, path string, report func(diff.Warning)) (map[string]interface{}, error) {
+	data, err := d.loadFile(ctx, path, report)
+	if err != nil {
+		return nil, err
+	}
+	return d.prepare(ctx, path, data, report)
+}
+
+// loadFile parses and resolves a file without preparing it.
+func (d *Differ) loadFile(ctx context.Context, path string, report func(diff.Warning)) (map[string]interface{}, error) {
+	data, err := d.fileParser.ParseFile(ctx, path, d.strictOptions(path, report))
+	if err != nil {
+		return nil, err
+	}
+	return d.resolve(ctx, path, data)
+}
+
+func (d *Differ) parseReader(ctx context.Context, r io.Reader, format, source string, report func(diff.Warning)) (map[string]interface{}, error) {
+	data, err := d.fileParser.ParseReader(ctx, r, format, d.strictOptions(source, report))
+	if err != nil {
+		return nil, err
+	}
+	if data, err = d.resolve(ctx, "", data); err != nil {
+		return nil, err
+	}
+	return d.prepare(ctx, source, data, report)
+}
+
+func (d *Differ) valueObject(ctx context.Context, v any, source string, report func(diff.Warning)) (map[string]interface{}, error) {
+	data, err := toObject(v, d.maxInputSize)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = d.resolve(ctx, "", data); err != nil {
+		return nil, err
+	}
+	return d.prepare(ctx, source, data, report)
+}
+
+// strictOptions returns the parse options, with strict checks reporting
+// under source when enabled.
+func (d *Differ) strictOptions(source string, report func(diff.Warning)) parser.ParseOptions {
+	opts := d.parseOptions()
+	if d.strict {
+		opts.Warn = func(w diff.Warning) {
+			if w.Source == "" {
+				w.Source = source
+			} else {
+				w.Source = source + "/" + w.Source
+			}
+			report(w)
+		}
+	}
+	return opts
+}
+
+// resolve applies $ref resolution and env expansion to a document read from
+// file, which is empty for readers and values; they may only refer into
+// themselves. Referenced files must be inside the directory of file and
+// share its size limit.
+func (d *Differ) resolve(ctx context.Context, file string, data map[string]interface{}) (map[string]interface{}, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	if d.resolveRefs {
+		includes := parser.NewIncludes(ctx, file, d.parseOptions())
+		resolved, err := resolve.Refs(ctx, data, file, func(path string) (map[string]interface{}, error) {
+			return includes.ParseFile(d.fileParser, file, path, d.parseOptions())
+		}, d.maxNodes)
+		if err != nil {
+			return nil, err
+		}
+		data = resolved
+	}
+
+	if d.lookupEnv != nil {
+		if err := resolve.Expand(ctx, data, d.lookupEnv, d.maxNodes); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// prepare checks a parsed document against the limits and the schema.
+func (d *Differ) prepare(ctx context.Context, source string, data map[string]interface{}, report func(diff.Warning)) (map[string]interface{}, error) {
+	if err := d.checkLimits(ctx, data); err != nil {
+		return nil, err
+	}
+
+	if d.schema != nil {
+		// Defaults first, so that a required property with a default is
+		// not reported missing.
+		d.schema.ApplyDefaults(data)
+		for _, w := range d.schema.Validate(data) {
+			w.Source = source
+			report(w)
+		}
+	}
+
+	return data, nil
+}
+
+func (d *Differ) warn(w diff.Warning) {
+	if d.onWarning != nil {
+		d.onWarning(w)
+	}
+}
+
+// toObject converts v to the same shape the parsers produce: nested
+// map[string]interface{} and []interface{} with float64 numbers.
+func toObject(v any, maxSize int64) (map[string]interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode value: %w", err)
+	}
+	if maxSize > 0 && int64(len(raw)) > maxSize {
+		return nil, fmt.Errorf("%w of %d bytes", ErrInputTooLarge, maxSize)
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, fmt.Errorf("decode value: %w", err)
+	}
+
+	switch obj := decoded.(type) {
+	case nil:
+		return map[string]interface{}{}, nil
+	case map[string]interface{}:
+		return obj, nil
+	default:
+		return nil, fmt.Errorf("%w: got %T", ErrNotObject, v)
+	}
+}
+
+// buildTree diffs two documents and applies the tree-level passes enabled by options.
+func (d *Differ) buildTree(ctx context.Context, data1, data2 map[string]interface{}) ([]*diff.Node, error) {
+	nodes, err := d.getNodes(ctx, "", data1, data2)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.moveThreshold > 0 {
+		nodes, err = d.detectMoves(ctx, nodes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if d.redactor != nil {
+		nodes = d.redactor.Redact(nodes)
+	}
+
+	return nodes, nil
+}
+
+func (d *Differ) getNodes(ctx context.Context, parentPath string, data1, data2 map[string]interface{}) ([]*diff.Node, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	keys := utils.MergedSortedKeys(data1, data2)
 
 	var nodes []*diff.Node
@@ -91,51 +555,82 @@ func (d *Differ) getNodes(data1, data2 map[string]interface{}) []*diff.Node {
 		v1, ok1 := data1[key]
 		v2, ok2 := data2[key]
 
-		node := d.getNode(key, v1, ok1, v2, ok2)
+		node, err := d.getNode(ctx, parentPath, key, v1, ok1, v2, ok2)
+		if err != nil {
+			return nil, err
+		}
 		if node != nil {
 			nodes = append(nodes, node)
 		}
 	}
 
-	return nodes
+	return nodes, nil
 }
 
-func (d *Differ) getNode(key string, v1 interface{}, ok1 bool, v2 interface{}, ok2 bool) *diff.Node {
+func (d *Differ) getNode(ctx context.Context, parentPath, key string, v1 interface{}, ok1 bool, v2 interface{}, ok2 bool) (*diff.Node, error) {
 	switch {
 	case ok1 && !ok2:
 		return &diff.Node{
 			Type:  diff.NodeTypeRemoved,
 			Key:   key,
 			Value: v1,
-		}
+		}, nil
 	case !ok1 && ok2:
 		return &diff.Node{
 			Type:  diff.NodeTypeAdded,
 			Key:   key,
 			Value: v2,
-		}
+		}, nil
 	case ok1 && ok2:
 		m1, isMap1 := v1.(map[string]interface{})
 		m2, isMap2 := v2.(map[string]interface{})
 		if isMap1 && isMap2 {
-			children := d.getNodes(m1, m2)
+			children, err := d.getNodes(ctx, utils.JoinPath(parentPath, key), m1, m2)
+			if err != nil {
+				return nil, err
+			}
 			return &diff.Node{
 				Type:     diff.NodeTypeNested,
 				Key:      key,
 				Children: children,
-			}
+			}, nil
 		}
 
-		if !reflect.DeepEqual(v1, v2) {
-			return &diff.Node{
-				Type:     diff.NodeTypeChanged,
-				Key:      key,
-				OldValue: v1,
-				NewValue: v2,
+		if equal, note := d.compare(utils.JoinPath(parentPath, key), v1, v2); !equal {
+			if d.typeChanges {
+				if oldType, newType := diff.TypeOf(v1), diff.TypeOf(v2); isTypeChange(oldType, newType) {
+					return &diff.Node{
+						Type:     diff.NodeTypeTypeChanged,
+						Key:      key,
+						OldValue: v1,
+						NewValue: v2,
+						OldType:  oldType,
+						NewType:  newType,
+					}, nil
+				}
 			}
+
+			return &diff.Node{
+				Type:       diff.NodeTypeChanged,
+				Key:        key,
+				OldValue:   v1,
+				NewValue:   v2,
+				Annotation: note,
+			}, nil
 		}
 
-		return &diff.Node{Type: diff.NodeTypeUnchanged, Key: key, Value: v1}
+		return &diff.Node{Type: diff.NodeTypeUnchanged, Key: key, Value: v1}, nil
 	}
-	return nil
+	return nil, nil
+}
+
+func isTypeChange(oldType, newType string) bool {
+	return oldType != newType && oldType != diff.TypeNull && newType != diff.TypeNull
+}
+
+func (d *Differ) compare(path string, v1, v2 interface{}) (bool, string) {
+	if d.comparer != nil {
+		return d.comparer.Compare(path, v1, v2)
+	}
+	return reflect.DeepEqual(v1, v2), ""
 }
@@ -1,7 +1,16 @@
 package code
 
 import (
-	"code/internal/parser"
+	"code/compare"
+	"code/diff"
+	"code/formatter"
+	"code/merge"
+	"code/parser"
+	"code/redact"
+	"code/resolve"
+	"code/schema"
+	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -191,6 +200,334 @@ func TestGenDiff_JSONFormat(t *testing.T) {
 	runDiffTests(t, tests)
 }
 
+func TestDiffer_Template(t *testing.T) {
+	differ := NewDiffer(WithFormatterOptions(formatter.WithTemplatePath(fixturePath("changes.tmpl"))))
+
+	result, err := differ.GetDiff(context.Background(), fixturePath("nested1.json"), fixturePath("nested2.json"), "template")
+	require.NoError(t, err)
+	require.Equal(t, readExpected(t, "nested_template.txt"), strings.TrimRight(result, "\n"))
+
+	_, err = NewDiffer().GetDiff(context.Background(), fixturePath("nested1.json"), fixturePath("nested2.json"), "template")
+	require.ErrorIs(t, err, formatter.ErrTemplateRequired)
+}
+
+func TestDiffer_TypeChanges(t *testing.T) {
+	differ := NewDiffer(WithTypeChanges())
+
+	for _, format := range []string{"stylish", "plain", "json"} {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			result, err := differ.GetDiff(context.Background(), fixturePath("type_changes1.json"), fixturePath("type_changes2.yml"), format)
+			require.NoError(t, err)
+			require.Equal(t, readExpected(t, "type_changes_"+format+".txt"), result)
+		})
+	}
+
+	result, err := GenDiff(fixturePath("type_changes1.json"), fixturePath("type_changes2.yml"), "plain")
+	require.NoError(t, err)
+	require.Contains(t, result, "Property 'server.port' was updated. From '80' to 80")
+}
+
+func TestDiffer_Comparison(t *testing.T) {
+	differ := NewDiffer(WithComparison(
+		compare.Rule{Options: compare.Options{AbsTolerance: 1e-9, NormalizeWhitespace: true}},
+		compare.Rule{Pattern: "server.*", Options: compare.Options{NumericStrings: true, IgnoreCase: true}},
+	))
+
+	result, err := differ.GetDiff(context.Background(), fixturePath("tolerance1.json"), fixturePath("tolerance2.yml"), "plain")
+	require.NoError(t, err)
+	require.Equal(t, "Property 'timeout' was updated. From 30 to 45", result)
+
+	result, err = GenDiff(fixturePath("tolerance1.json"), fixturePath("tolerance2.yml"), "plain")
+	require.NoError(t, err)
+	require.Len(t, strings.Split(result, "\n"), 5)
+}
+
+func TestDiffer_SemanticComparison(t *testing.T) {
+	differ := NewDiffer(WithComparison(compare.Rule{Options: compare.Options{Semantics: compare.Semantics()}}))
+
+	for _, format := range []string{"stylish", "plain"} {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			result, err := differ.GetDiff(context.Background(), fixturePath("semantic1.yml"), fixturePath("semantic2.yml"), format)
+			require.NoError(t, err)
+			require.Equal(t, readExpected(t, "semantic_"+format+".txt"), result)
+		})
+	}
+}
+
+func TestDiffer_Redaction(t *testing.T) {
+	key := []byte("test key")
+	differ := NewDiffer(WithRedactionKey(key), WithRedaction())
+
+	result, err := differ.GetDiff(context.Background(), fixturePath("secrets1.yml"), fixturePath("secrets2.yml"), "stylish")
+	require.NoError(t, err)
+	require.Equal(t, readExpected(t, "secrets_stylish.txt"), result)
+	require.NotContains(t, result, "hunter2")
+
+	nodes, err := differ.DiffValues(context.Background(),
+		map[string]any{"auth": map[string]any{"token": "a"}},
+		map[string]any{"auth": map[string]any{"token": "b"}},
+	)
+	require.NoError(t, err)
+	require.Equal(t, redact.NewWithKey(key).Marker("b"), nodes[0].Children[0].NewValue)
+}
+
+func TestDiffer_Schema(t *testing.T) {
+	s, err := schema.Load(fixturePath("schema.json"))
+	require.NoError(t, err)
+
+	var warnings []diff.Warning
+	differ := NewDiffer(WithSchema(s), WithWarningHandler(func(w diff.Warning) {
+		warnings = append(warnings, w)
+	}))
+
+	result, err := differ.GetDiff(context.Background(), fixturePath("schema1.yml"), fixturePath("schema2.yml"), "plain")
+	require.NoError(t, err)
+	require.Equal(t, "Property 'replicas' was updated. From 1 to 0\n"+
+		"Property 'server.protocol' was updated. From 'http' to 'ftp'\n\n"+
+		"warning: "+fixturePath("schema2.yml")+": replicas: value is less than minimum 1\n"+
+		"warning: "+fixturePath("schema2.yml")+": server.protocol: value is not one of the allowed values", result)
+	require.Equal(t, []diff.Warning{
+		{Source: fixturePath("schema2.yml"), Path: "replicas", Message: "value is less than minimum 1"},
+		{Source: fixturePath("schema2.yml"), Path: "server.protocol", Message: "value is not one of the allowed values"},
+	}, warnings)
+	require.NotContains(t, result, `"ftp"`)
+
+	result, err = GenDiff(fixturePath("schema1.yml"), fixturePath("schema2.yml"), "plain")
+	require.NoError(t, err)
+	require.Contains(t, result, "Property 'server.port' was removed")
+
+	warnings = nil
+	_, err = differ.DiffValues(context.Background(), map[string]any{"name": "web"}, map[string]any{})
+	require.NoError(t, err)
+	require.Equal(t, []diff.Warning{{Source: "second value", Message: `missing required property "name"`}}, warnings)
+
+	withDefault, err := schema.Parse([]byte(`{"required": ["mode"], "properties": {"mode": {"default": "fast"}}}`))
+	require.NoError(t, err)
+	warnings = nil
+	nodes, err := NewDiffer(WithSchema(withDefault), WithWarningHandler(func(w diff.Warning) {
+		warnings = append(warnings, w)
+	})).DiffValues(context.Background(), map[string]any{}, map[string]any{"mode": "fast"})
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+	require.Equal(t, diff.NodeTypeUnchanged, nodes[0].Type)
+}
+
+func TestGenDiff_XML(t *testing.T) {
+	result, err := GenDiff(fixturePath("pom1.xml"), fixturePath("pom2.xml"), "plain")
+	require.NoError(t, err)
+	require.Equal(t, readExpected(t, "pom_plain.txt"), result)
+
+	differ := NewDiffer(WithParser(&parser.XMLParser{Namespaces: parser.XMLNamespacesURI}, ".xml"))
+	result, err = differ.GetDiff(context.Background(), fixturePath("pom1.xml"), fixturePath("pom2.xml"), "plain")
+	require.NoError(t, err)
+	require.Contains(t, result, "Property '{http://maven.apache.org/POM/4.0.0}project.{http://maven.apache.org/POM/4.0.0}version' was updated")
+
+	differ = NewDiffer(WithParser(&parser.XMLParser{Arrays: []string{"dependency"}}, ".xml"))
+	result, err = differ.GetDiff(context.Background(), fixturePath("pom1.xml"), fixturePath("pom2.xml"), "stylish")
+	require.NoError(t, err)
+	require.Contains(t, result, "          - dependency: [\n")
+	require.Contains(t, result, "          + dependency: [\n")
+}
+
+func TestGenDiff_PropertiesAndHOCON(t *testing.T) {
+	result, err := GenDiff(fixturePath("app1.properties"), fixturePath("app2.hocon"), "plain")
+	require.NoError(t, err)
+	require.Equal(t, readExpected(t, "properties_hocon_plain.txt"), result)
+}
+
+func TestGenDiff_HCL(t *testing.T) {
+	result, err := GenDiff(fixturePath("staging.tfvars"), fixturePath("prod.tfvars"), "plain")
+	require.NoError(t, err)
+	require.Equal(t, readExpected(t, "tfvars_plain.txt"), result)
+}
+
+func TestGenDiff_LenientJSON(t *testing.T) {
+	result, err := GenDiff(fixturePath("settings1.jsonc"), fixturePath("settings2.jsonc"), "plain")
+	require.NoError(t, err)
+	require.Equal(t, readExpected(t, "jsonc_plain.txt"), result)
+}
+
+func TestDiffer_CSV(t *testing.T) {
+	differ := NewDiffer(WithParser(&parser.CSVParser{Key: "id"}, ".csv"))
+	result, err := differ.GetDiff(context.Background(), fixturePath("pricing1.csv"), fixturePath("pricing2.csv"), "plain")
+	require.NoError(t, err)
+	require.Equal(t, readExpected(t, "csv_plain.txt"), result)
+
+	result, err = GenDiff(fixturePath("pricing1.csv"), fixturePath("pricing2.csv"), "plain")
+	require.NoError(t, err)
+	require.Contains(t, result, "Property 'Team.seats' was updated. From '20' to '25'")
+}
+
+func TestGenDiff_CompressedAndArchived(t *testing.T) {
+	result, err := GenDiff(fixturePath("file1.json"), fixturePath("file2.json.gz"), "plain")
+	require.NoError(t, err)
+	require.Equal(t, readExpected(t, "flat_diff_plain.txt"), result)
+
+	result, err = GenDiff(fixturePath("snapshot1.tar.gz"), fixturePath("snapshot2.zip"), "plain")
+	require.NoError(t, err)
+	require.Equal(t, readExpected(t, "archive_plain.txt"), result)
+}
+
+func TestDiffer_YAMLAnchors(t *testing.T) {
+	result, err := GenDiff(fixturePath("ci1.yml"), fixturePath("ci2.yml"), "plain")
+	require.NoError(t, err)
+	require.Equal(t, readExpected(t, "anchors_plain.txt"), result)
+
+	differ := NewDiffer(WithParser(&parser.YAMLParser{KeepAnchors: true}, ".yml"))
+	result, err = differ.GetDiff(context.Background(), fixturePath("ci1.yml"), fixturePath("ci2.yml"), "plain")
+	require.NoError(t, err)
+	require.Equal(t, readExpected(t, "anchors_kept_plain.txt"), result)
+}
+
+func TestDiffer_Strict(t *testing.T) {
+	path1, path2 := fixturePath("strict1.json"), fixturePath("strict2.yml")
+
+	var warnings []diff.Warning
+	differ := NewDiffer(WithStrict(), WithWarningHandler(func(w diff.Warning) {
+		warnings = append(warnings, w)
+	}))
+	result, err := differ.GetDiff(context.Background(), path1, path2, "json")
+	require.NoError(t, err)
+
+	require.Equal(t, []diff.Warning{
+		{Source: path1, Line: 5, Path: "server.port", Message: "duplicate key, first defined on line 4; the last value is used"},
+		{Source: path2, Line: 6, Path: "status_codes.200", Message: `int key is compared as the string "200"`},
+		{Source: path2, Line: 7, Path: "status_codes.404", Message: `int key is compared as the string "404"`},
+		{Source: path2, Line: 6, Path: "status_codes", Message: "mapping mixes string and non-string keys"},
+	}, warnings)
+
+	var output struct {
+		Warnings []diff.Warning `json:"warnings"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result), &output))
+	require.Equal(t, warnings, output.Warnings)
+
+	warnings = nil
+	differ = NewDiffer(WithWarningHandler(func(w diff.Warning) {
+		warnings = append(warnings, w)
+	}))
+	result, err = differ.GetDiff(context.Background(), path1, path2, "json")
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+	require.NotContains(t, result, "warnings")
+}
+
+func TestDiffer_Resolve(t *testing.T) {
+	path1, path2 := fixturePath("resolve", "deploy1.yml"), fixturePath("resolve", "deploy2.json")
+	vars, err := resolve.LoadEnvFile(fixturePath("resolve", "prod.env"))
+	require.NoError(t, err)
+
+	differ := NewDiffer(
+		WithParser(&parser.YAMLParser{Tags: map[string]parser.YAMLTagResolver{"!include": parser.YAMLInclude}}, ".yml"),
+		WithEnvExpansion(resolve.Vars(vars)),
+		WithRefResolution(),
+	)
+	result, err := differ.GetDiff(context.Background(), path1, path2, "plain")
+	require.NoError(t, err)
+	require.Equal(t, readExpected(t, "resolve_plain.txt"), result)
+
+	differ = NewDiffer(WithEnvExpansion(resolve.Vars(nil)))
+	_, err = differ.GetDiff(context.Background(), path1, path2, "plain")
+	require.ErrorIs(t, err, resolve.ErrUnsetVariable)
+}
+
+func TestDiffer_ResolveRestrictions(t *testing.T) {
+	ctx := context.Background()
+	differ := NewDiffer(WithRefResolution())
+
+	// Readers and values have no directory: refs into themselves work, refs
+	// to files do not, whatever the working directory holds.
+	_, err := differ.DiffReaders(ctx,
+		strings.NewReader(`{"db": {"$ref": "`+fixturePath("resolve", "shared", "db.json")+`"}}`),
+		strings.NewReader("{}"), "json", "json")
+	require.ErrorIs(t, err, resolve.ErrRef)
+
+	nodes, err := differ.DiffValues(ctx,
+		map[string]any{"base": map[string]any{"port": 80}, "web": map[string]any{"$ref": "#/base"}},
+		map[string]any{"base": map[string]any{"port": 80}, "web": map[string]any{"port": 80}},
+	)
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+	require.Equal(t, "web", nodes[1].Key)
+	require.Equal(t, []*diff.Node{{Type: diff.NodeTypeUnchanged, Key: "port", Value: 80.0}}, nodes[1].Children)
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	outside := write("../outside.json", `{"secret": "x"}`)
+	write("a.json", `{"a": "`+strings.Repeat("x", 40)+`"}`)
+	write("b.json", `{"b": "`+strings.Repeat("x", 40)+`"}`)
+	escape := write("escape.json", `{"s": {"$ref": "`+outside+`"}}`)
+	both := write("both.json", `{"a": {"$ref": "a.json"}, "b": {"$ref": "b.json"}}`)
+
+	_, err = differ.GetDiff(ctx, escape, escape, "plain")
+	require.ErrorIs(t, err, parser.ErrIncludeOutsideDir)
+
+	// Each file fits the size limit, but not the two refs together.
+	_, err = NewDiffer(WithRefResolution(), WithMaxInputSize(100)).GetDiff(ctx, both, both, "plain")
+	require.NoError(t, err)
+	_, err = NewDiffer(WithRefResolution(), WithMaxInputSize(60)).GetDiff(ctx, both, both, "plain")
+	require.ErrorIs(t, err, ErrInputTooLarge)
+}
+
+func TestDiffer_Layers(t *testing.T) {
+	left := []string{fixturePath("layers", "base.yml"), fixturePath("layers", "prod.yml")}
+	right := []string{fixturePath("layers", "base.yml"), fixturePath("layers", "staging.yml")}
+
+	tests := []struct {
+		name         string
+		opts         []Option
+		expectedFile string
+	}{
+		{name: "replace lists", expectedFile: "layers_stylish.txt"},
+		{
+			name: "merge lists by key",
+			opts: []Option{WithListMerge(
+				merge.Rule{Pattern: "containers", Lists: merge.ByKey, Key: "name"},
+				merge.Rule{Pattern: "containers.env", Lists: merge.Append},
+			)},
+			expectedFile: "layers_by_key_stylish.txt",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewDiffer(tt.opts...).GetLayeredDiff(context.Background(), left, right, "stylish")
+			require.NoError(t, err)
+			require.Equal(t, readExpected(t, tt.expectedFile), result)
+		})
+	}
+
+	_, err := NewDiffer().GetLayeredDiff(context.Background(), left, nil, "stylish")
+	require.ErrorIs(t, err, ErrEmptyPath)
+
+	_, err = NewDiffer().GetLayeredDiff(context.Background(), left, []string{fixturePath("layers", "missing.yml")}, "stylish")
+	require.ErrorContains(t, err, "missing.yml")
+}
+
+func TestGenDiff_MultilineStrings(t *testing.T) {
+	path1, path2 := fixturePath("multiline1.yml"), fixturePath("multiline2.yml")
+	differ := NewDiffer(WithFormatterOptions(formatter.WithWordDiff(80)))
+	for _, format := range []string{"stylish", "plain"} {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			result, err := differ.GetDiff(context.Background(), path1, path2, format)
+			require.NoError(t, err)
+			require.Equal(t, readExpected(t, "multiline_"+format+".txt"), result)
+		})
+	}
+
+	result, err := GenDiff(path1, path2, "plain")
+	require.NoError(t, err)
+	require.Equal(t, readExpected(t, "multiline_lines_plain.txt"), result)
+}
+
 func TestGenDiff_Errors(t *testing.T) {
 	tests := []diffTestCase{
 		{
@@ -258,11 +595,93 @@ func TestGenDiff_Nested(t *testing.T) {
 	runDiffTests(t, tests)
 }
 
+func TestDiffer_WriteDiff(t *testing.T) {
+	var sb strings.Builder
+
+	err := NewDiffer().WriteDiff(context.Background(), &sb, fixturePath("nested1.json"), fixturePath("nested2.yml"), "plain")
+	require.NoError(t, err)
+	require.Equal(t, readExpected(t, "nested_plain.txt"), sb.String())
+
+	err = NewDiffer().WriteDiff(context.Background(), &sb, "", fixturePath("nested2.yml"), "plain")
+	require.ErrorIs(t, err, ErrEmptyPath)
+}
+
+func TestDiffer_DiffReaders(t *testing.T) {
+	ctx := context.Background()
+	d := NewDiffer()
+
+	nodes, err := d.DiffReaders(ctx, strings.NewReader(`{"a": 1, "b": {"c": true}}`), strings.NewReader("a: 2\nb:\n  c: true\n"), "json", "yml")
+	require.NoError(t, err)
+	require.Equal(t, []*diff.Node{
+		{Type: diff.NodeTypeChanged, Key: "a", OldValue: 1.0, NewValue: 2.0},
+		{Type: diff.NodeTypeNested, Key: "b", Children: []*diff.Node{
+			{Type: diff.NodeTypeUnchanged, Key: "c", Value: true},
+		}},
+	}, nodes)
+
+	_, err = d.DiffReaders(ctx, nil, strings.NewReader("{}"), "json", "json")
+	require.ErrorIs(t, err, ErrNilReader)
+
+	_, err = d.DiffReaders(ctx, strings.NewReader("{}"), nil, "json", "json")
+	require.ErrorIs(t, err, ErrNilReader)
+
+	_, err = d.DiffReaders(ctx, strings.NewReader("{}"), strings.NewReader("{}"), "json", "toml")
+	require.ErrorIs(t, err, parser.ErrUnsupportedFormat)
+
+	_, err = d.DiffReaders(ctx, strings.NewReader("{"), strings.NewReader("{}"), "json", "json")
+	require.Error(t, err)
+}
+
+func TestDiffer_DiffValues(t *testing.T) {
+	type db struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	type config struct {
+		Name  string   `json:"name"`
+		DB    db       `json:"db"`
+		Tags  []string `json:"tags,omitempty"`
+		Debug bool     `json:"-"`
+	}
+
+	ctx := context.Background()
+	d := NewDiffer()
+
+	nodes, err := d.DiffValues(
+		ctx,
+		config{Name: "svc", DB: db{Host: "localhost", Port: 5432}, Debug: true},
+		map[string]any{"name": "svc", "db": map[string]any{"host": "db", "port": 5432}, "tags": []string{"a"}},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []*diff.Node{
+		{Type: diff.NodeTypeNested, Key: "db", Children: []*diff.Node{
+			{Type: diff.NodeTypeChanged, Key: "host", OldValue: "localhost", NewValue: "db"},
+			{Type: diff.NodeTypeUnchanged, Key: "port", Value: 5432.0},
+		}},
+		{Type: diff.NodeTypeUnchanged, Key: "name", Value: "svc"},
+		{Type: diff.NodeTypeAdded, Key: "tags", Value: []interface{}{"a"}},
+	}, nodes)
+
+	nodes, err = d.DiffValues(ctx, nil, map[string]int{"a": 1})
+	require.NoError(t, err)
+	require.Equal(t, []*diff.Node{{Type: diff.NodeTypeAdded, Key: "a", Value: 1.0}}, nodes)
+
+	_, err = d.DiffValues(ctx, []int{1}, nil)
+	require.ErrorIs(t, err, ErrNotObject)
+
+	_, err = d.DiffValues(ctx, nil, "text")
+	require.ErrorIs(t, err, ErrNotObject)
+
+	_, err = d.DiffValues(ctx, make(chan int), nil)
+	require.Error(t, err)
+}
+
 func TestDiffer_getNodeReturnsNil(t *testing.T) {
 	d := NewDiffer()
 
-	node := d.getNode("missing", nil, false, nil, false)
+	node, err := d.getNode(context.Background(), "", "missing", nil, false, nil, false)
 
+	require.NoError(t, err)
 	require.Nil(t, node)
 }
 
@@ -285,11 +704,61 @@ func TestGenDiff_FormatError(t *testing.T) {
 
 	differ := NewDiffer(WithFileParser(fp))
 
-	_, err := differ.GetDiff(path, path, "json")
+	_, err := differ.GetDiff(context.Background(), path, path, "json")
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "format diff")
 }
 
+type keysFormatter struct{}
+
+func (f *keysFormatter) Format(nodes []*diff.Node) (string, error) {
+	keys := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		keys = append(keys, string(node.Type)+":"+node.Key)
+	}
+	return strings.Join(keys, ","), nil
+}
+
+func TestDiffer_CustomRegistries(t *testing.T) {
+	registry := formatter.NewRegistry()
+	require.NoError(t, registry.Register("keys", func(formatter.Options) (formatter.Formatter, error) {
+		return &keysFormatter{}, nil
+	}))
+
+	tempDir := t.TempDir()
+	path1 := filepath.Join(tempDir, "a.custom")
+	path2 := filepath.Join(tempDir, "b.custom")
+	require.NoError(t, os.WriteFile(path1, []byte(`{"a": 1, "b": 2}`), 0o644))
+	require.NoError(t, os.WriteFile(path2, []byte(`{"b": 2, "c": 3}`), 0o644))
+
+	differ := NewDiffer(
+		WithFormatterRegistry(registry),
+		WithParser(&parser.JSONParser{}, ".custom"),
+	)
+
+	result, err := differ.GetDiff(context.Background(), path1, path2, "keys")
+	require.NoError(t, err)
+	require.Equal(t, "removed:a,unchanged:b,added:c", result)
+
+	_, err = NewDiffer().GetDiff(context.Background(), path1, path2, "keys")
+	require.ErrorIs(t, err, parser.ErrUnsupportedFormat)
+
+	_, err = NewDiffer(WithParser(&parser.JSONParser{}, ".custom")).GetDiff(context.Background(), path1, path2, "keys")
+	require.ErrorIs(t, err, formatter.ErrUnknownFormat)
+
+	fp := parser.NewFileParser()
+	for _, opts := range [][]Option{
+		{WithParser(&parser.JSONParser{}, ".custom"), WithFileParser(fp), WithFormatterRegistry(registry)},
+		{WithFileParser(fp), WithParser(&parser.JSONParser{}, ".custom"), WithFormatterRegistry(registry)},
+	} {
+		result, err = NewDiffer(opts...).GetDiff(context.Background(), path1, path2, "keys")
+		require.NoError(t, err)
+		require.Equal(t, "removed:a,unchanged:b,added:c", result)
+	}
+	_, err = fp.Parse(path1)
+	require.ErrorIs(t, err, parser.ErrUnsupportedFormat)
+}
+
 func runDiffTests(t *testing.T, tests []diffTestCase) {
 	t.Helper()
 
@@ -4,6 +4,7 @@ go 1.24.5
 
 require (
 	github.com/golang/mock v1.6.0
+	github.com/klauspost/compress v1.18.0
 	github.com/stretchr/testify v1.11.1
 	github.com/urfave/cli/v3 v3.6.1
 	gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,259 @@
+// Package textdiff computes line and word level differences between strings.
+package textdiff
+
+import (
+	"regexp"
+	"strings"
+)
+
+type OpKind int
+
+const (
+	Equal OpKind = iota
+	Delete
+	Insert
+)
+
+// Op is one token of an edit script.
+type Op struct {
+	Kind OpKind
+	Text string
+}
+
+// Hunk is a run of changes with surrounding context, numbered like unified
+// diff hunks (1-based start lines).
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Ops                []Op
+}
+
+// IsMultiline reports whether s spans several lines.
+func IsMultiline(s string) bool {
+	return strings.Contains(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// NoNewline follows the last line of a side that lacks the final newline the
+// other side has, as in unified diffs.
+const NoNewline = `\ No newline at end of file`
+
+// Lines diffs a and b line by line. A trailing newline does not produce an
+// empty last line; when only one side has it, the other ends with a
+// NoNewline line. Carriage returns are shown as `\r`.
+func Lines(a, b string) []Op {
+	la, lb := splitLines(a), splitLines(b)
+	if endsWithNewline(a) != endsWithNewline(b) {
+		if endsWithNewline(a) {
+			lb = append(lb, NoNewline)
+		} else {
+			la = append(la, NoNewline)
+		}
+	}
+	return diff(la, lb)
+}
+
+// Changed reports whether ops contain a deletion or an insertion.
+func Changed(ops []Op) bool {
+	for _, op := range ops {
+		if op.Kind != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+var wordPattern = regexp.MustCompile(`\s+|[^\s]+`)
+
+// Words diffs a and b by words, keeping whitespace runs as separate tokens.
+func Words(a, b string) []Op {
+	return diff(wordPattern.FindAllString(a, -1), wordPattern.FindAllString(b, -1))
+}
+
+// ChangedLines counts changed lines: within each block of consecutive
+// changes, a deleted and an inserted line count as one modified line.
+func ChangedLines(ops []Op) int {
+	total, deleted, inserted := 0, 0, 0
+	flush := func() {
+		total += max(deleted, inserted)
+		deleted, inserted = 0, 0
+	}
+
+	for _, op := range ops {
+		switch op.Kind {
+		case Delete:
+			deleted++
+		case Insert:
+			inserted++
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return total
+}
+
+// Hunks groups a line edit script into hunks with up to context unchanged
+// lines around each change. Changes separated by at most 2*context
+// unchanged lines share a hunk.
+func Hunks(ops []Op, context int) []Hunk {
+	var hunks []Hunk
+
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == Equal {
+			i++
+			continue
+		}
+
+		start := max(i-context, 0)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].Kind != Equal {
+				end = j
+				continue
+			}
+			if j-end > 2*context {
+				break
+			}
+		}
+		stop := min(end+context+1, len(ops))
+
+		hunks = append(hunks, newHunk(ops, start, stop))
+		i = stop
+	}
+
+	return hunks
+}
+
+func newHunk(ops []Op, start, stop int) Hunk {
+	h := Hunk{OldStart: 1, NewStart: 1}
+	for _, op := range ops[:start] {
+		if op.Kind != Insert {
+			h.OldStart++
+		}
+		if op.Kind != Delete {
+			h.NewStart++
+		}
+	}
+
+	h.Ops = ops[start:stop]
+	for _, op := range h.Ops {
+		if op.Text == NoNewline {
+			continue
+		}
+		if op.Kind != Insert {
+			h.OldLines++
+		}
+		if op.Kind != Delete {
+			h.NewLines++
+		}
+	}
+	return h
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.ReplaceAll(strings.TrimSuffix(s, "\n"), "\r", `\r`)
+	return strings.Split(s, "\n")
+}
+
+func endsWithNewline(s string) bool {
+	return strings.HasSuffix(s, "\n")
+}
+
+// maxEdits bounds the work spent on very different inputs; beyond it the
+// whole of a is reported as replaced by b.
+const maxEdits = 1000
+
+// diff returns a shortest edit script from a to b using Myers' algorithm.
+func diff(a, b []string) []Op {
+	n, m := len(a), len(b)
+	maxD := min(n+m, maxEdits)
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, offset)
+			}
+		}
+	}
+
+	return replaceAll(a, b)
+}
+
+func replaceAll(a, b []string) []Op {
+	ops := make([]Op, 0, len(a)+len(b))
+	for _, line := range a {
+		ops = append(ops, Op{Kind: Delete, Text: line})
+	}
+	for _, line := range b {
+		ops = append(ops, Op{Kind: Insert, Text: line})
+	}
+	return ops
+}
+
+func backtrack(trace [][]int, a, b []string, offset int) []Op {
+	x, y := len(a), len(b)
+	var ops []Op
+
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, Op{Kind: Equal, Text: a[x]})
+		}
+
+		if x == prevX {
+			y--
+			ops = append(ops, Op{Kind: Insert, Text: b[y]})
+		} else {
+			x--
+			ops = append(ops, Op{Kind: Delete, Text: a[x]})
+		}
+	}
+
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, Op{Kind: Equal, Text: a[x]})
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
@@ -0,0 +1,146 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected []Op
+	}{
+		{name: "both empty", expected: nil},
+		{
+			name: "modified line",
+			a:    "a\nb\nc\n",
+			b:    "a\nB\nc",
+			expected: []Op{
+				{Kind: Equal, Text: "a"},
+				{Kind: Delete, Text: "b"},
+				{Kind: Insert, Text: "B"},
+				{Kind: Equal, Text: "c"},
+				{Kind: Insert, Text: NoNewline},
+			},
+		},
+		{
+			name: "final newline only",
+			a:    "a\nb",
+			b:    "a\nb\n",
+			expected: []Op{
+				{Kind: Equal, Text: "a"},
+				{Kind: Equal, Text: "b"},
+				{Kind: Delete, Text: NoNewline},
+			},
+		},
+		{
+			name: "carriage returns",
+			a:    "a\r\nb\r\n",
+			b:    "a\nb\n",
+			expected: []Op{
+				{Kind: Delete, Text: `a\r`},
+				{Kind: Delete, Text: `b\r`},
+				{Kind: Insert, Text: "a"},
+				{Kind: Insert, Text: "b"},
+			},
+		},
+		{
+			name: "added and removed",
+			a:    "a\nb",
+			b:    "b\nc",
+			expected: []Op{
+				{Kind: Delete, Text: "a"},
+				{Kind: Equal, Text: "b"},
+				{Kind: Insert, Text: "c"},
+			},
+		},
+		{
+			name:     "from empty",
+			b:        "x\ny",
+			expected: []Op{{Kind: Insert, Text: "x"}, {Kind: Insert, Text: "y"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, Lines(tt.a, tt.b))
+		})
+	}
+}
+
+func TestLinesFallsBackWhenTooDifferent(t *testing.T) {
+	var a, b []string
+	for i := 0; i < maxEdits; i++ {
+		a = append(a, "a")
+		b = append(b, "b")
+	}
+
+	ops := Lines(strings.Join(a, "\n"), strings.Join(b, "\n"))
+	require.Len(t, ops, 2*maxEdits)
+	require.Equal(t, Delete, ops[0].Kind)
+	require.Equal(t, Insert, ops[len(ops)-1].Kind)
+}
+
+func TestWords(t *testing.T) {
+	ops := Words("the quick brown fox", "the quick red fox")
+	require.Equal(t, []Op{
+		{Kind: Equal, Text: "the"},
+		{Kind: Equal, Text: " "},
+		{Kind: Equal, Text: "quick"},
+		{Kind: Equal, Text: " "},
+		{Kind: Delete, Text: "brown"},
+		{Kind: Insert, Text: "red"},
+		{Kind: Equal, Text: " "},
+		{Kind: Equal, Text: "fox"},
+	}, ops)
+}
+
+func TestChangedLines(t *testing.T) {
+	require.Equal(t, 0, ChangedLines(Lines("a\nb", "a\nb")))
+	require.Equal(t, 1, ChangedLines(Lines("a\nb\nc", "a\nB\nc")))
+	require.Equal(t, 4, ChangedLines(Lines("a\nb\nc\nd", "A\nb\nC\nD\nE")))
+}
+
+func TestHunks(t *testing.T) {
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12"
+	changed := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve"
+
+	hunks := Hunks(Lines(old, changed), 1)
+	require.Len(t, hunks, 2)
+
+	require.Equal(t, 2, hunks[0].OldStart)
+	require.Equal(t, 3, hunks[0].OldLines)
+	require.Equal(t, 2, hunks[0].NewStart)
+	require.Equal(t, 3, hunks[0].NewLines)
+	require.Equal(t, []Op{
+		{Kind: Equal, Text: "2"},
+		{Kind: Delete, Text: "3"},
+		{Kind: Insert, Text: "three"},
+		{Kind: Equal, Text: "4"},
+	}, hunks[0].Ops)
+
+	require.Equal(t, 11, hunks[1].OldStart)
+	require.Equal(t, 2, hunks[1].OldLines)
+	require.Equal(t, 11, hunks[1].NewStart)
+	require.Equal(t, 2, hunks[1].NewLines)
+
+	merged := Hunks(Lines(old, changed), 3)
+	require.Len(t, merged, 2)
+
+	merged = Hunks(Lines(old, changed), 4)
+	require.Len(t, merged, 1)
+	require.Equal(t, 1, merged[0].OldStart)
+	require.Equal(t, 12, merged[0].OldLines)
+
+	require.Empty(t, Hunks(Lines("a", "a"), 3))
+}
+
+func TestIsMultiline(t *testing.T) {
+	require.False(t, IsMultiline("single"))
+	require.False(t, IsMultiline("single\n"))
+	require.True(t, IsMultiline("first\nsecond"))
+}
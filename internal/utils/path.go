@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchPath reports whether a dotted key path matches pattern. Patterns are
+// dotted too; each segment is matched with path.Match, and a "**" segment
+// matches any number of segments. An empty pattern matches every path.
+func MatchPath(pattern, keyPath string) bool {
+	if pattern == "" {
+		return true
+	}
+	return matchSegments(strings.Split(pattern, "."), splitPath(keyPath))
+}
+
+func splitPath(keyPath string) []string {
+	if keyPath == "" {
+		return nil
+	}
+	return strings.Split(keyPath, ".")
+}
+
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], segments[0]); err != nil || !ok {
+			return false
+		}
+
+		pattern = pattern[1:]
+		segments = segments[1:]
+	}
+
+	return len(segments) == 0
+}
+
+// JoinPath appends key to a dotted parent path.
+func JoinPath(parentPath, key string) string {
+	if parentPath == "" {
+		return key
+	}
+	return parentPath + "." + key
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{pattern: "", path: "a.b", expected: true},
+		{pattern: "a.b", path: "a.b", expected: true},
+		{pattern: "a.b", path: "a.b.c", expected: false},
+		{pattern: "a.*", path: "a.b", expected: true},
+		{pattern: "a.*", path: "a.b.c", expected: false},
+		{pattern: "**", path: "a.b.c", expected: true},
+		{pattern: "**.port", path: "port", expected: true},
+		{pattern: "**.port", path: "server.http.port", expected: true},
+		{pattern: "server.**", path: "server", expected: true},
+		{pattern: "server.**.port", path: "server.http.port", expected: true},
+		{pattern: "*_key", path: "api_key", expected: true},
+		{pattern: "[", path: "[", expected: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.pattern+"|"+tt.path, func(t *testing.T) {
+			require.Equal(t, tt.expected, MatchPath(tt.pattern, tt.path))
+		})
+	}
+}
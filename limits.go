@@ -0,0 +1,103 @@
+package code
+
+import (
+	"code/parser"
+	"context"
+	"fmt"
+)
+
+var (
+	ErrInputTooLarge    = parser.ErrInputTooLarge
+	ErrMaxDepthExceeded = parser.ErrMaxDepthExceeded
+	ErrMaxNodesExceeded = parser.ErrMaxNodesExceeded
+)
+
+// ctxCheckInterval is how many values are visited between context checks.
+const ctxCheckInterval = 1024
+
+// WithMaxInputSize limits the size of each input in bytes. Larger inputs fail
+// with ErrInputTooLarge before they are parsed.
+func WithMaxInputSize(size int64) Option {
+	return func(d *Differ) {
+		d.maxInputSize = size
+	}
+}
+
+// WithMaxDepth limits how deeply values may be nested in each input.
+// Top-level keys are at depth 1. Deeper inputs fail with ErrMaxDepthExceeded,
+// JSON and YAML files while they are parsed.
+func WithMaxDepth(depth int) Option {
+	return func(d *Differ) {
+		d.maxDepth = depth
+	}
+}
+
+// WithMaxNodes limits the number of values (object keys and array elements)
+// in each input. Larger inputs fail with ErrMaxNodesExceeded, JSON and YAML
+// files while they are parsed.
+func WithMaxNodes(count int) Option {
+	return func(d *Differ) {
+		d.maxNodes = count
+	}
+}
+
+func (d *Differ) parseOptions() parser.ParseOptions {
+	return parser.ParseOptions{MaxSize: d.maxInputSize, MaxDepth: d.maxDepth, MaxNodes: d.maxNodes}
+}
+
+// checkLimits walks a parsed document without recursion, so that hostile
+// inputs are rejected before the recursive diff and formatters see them.
+// It covers parsers that do not enforce the limits themselves, merged layers
+// and resolved references.
+func (d *Differ) checkLimits(ctx context.Context, doc map[string]interface{}) error {
+	if d.maxDepth <= 0 && d.maxNodes <= 0 {
+		return nil
+	}
+
+	type entry struct {
+		value interface{}
+		depth int
+	}
+
+	stack := []entry{{value: doc}}
+	visited := 0
+
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		push := func(v interface{}) error {
+			visited++
+			if d.maxNodes > 0 && visited > d.maxNodes {
+				return fmt.Errorf("%w: limit %d", ErrMaxNodesExceeded, d.maxNodes)
+			}
+			if d.maxDepth > 0 && current.depth+1 > d.maxDepth {
+				return fmt.Errorf("%w: limit %d", ErrMaxDepthExceeded, d.maxDepth)
+			}
+			if visited%ctxCheckInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+			stack = append(stack, entry{value: v, depth: current.depth + 1})
+			return nil
+		}
+
+		switch val := current.value.(type) {
+		case map[string]interface{}:
+			for _, v := range val {
+				if err := push(v); err != nil {
+					return err
+				}
+			}
+		case []interface{}:
+			for _, v := range val {
+				if err := push(v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
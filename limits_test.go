@@ -0,0 +1,132 @@
+package code
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func nestedObject(depth int) map[string]interface{} {
+	root := map[string]interface{}{}
+	current := root
+	for i := 0; i < depth-1; i++ {
+		next := map[string]interface{}{}
+		current["k"] = next
+		current = next
+	}
+	current["k"] = "leaf"
+	return root
+}
+
+func TestDiffer_Limits(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		v1        any
+		expectErr error
+	}{
+		{name: "no limits", v1: nestedObject(50)},
+		{name: "depth within limit", opts: []Option{WithMaxDepth(3)}, v1: nestedObject(3)},
+		{name: "depth exceeded", opts: []Option{WithMaxDepth(3)}, v1: nestedObject(4), expectErr: ErrMaxDepthExceeded},
+		{
+			name:      "depth exceeded in array",
+			opts:      []Option{WithMaxDepth(2)},
+			v1:        map[string]any{"list": []any{[]any{1}}},
+			expectErr: ErrMaxDepthExceeded,
+		},
+		{name: "nodes within limit", opts: []Option{WithMaxNodes(3)}, v1: map[string]any{"a": 1, "b": []any{1}}},
+		{name: "nodes exceeded", opts: []Option{WithMaxNodes(3)}, v1: map[string]any{"a": 1, "b": []any{1, 2}}, expectErr: ErrMaxNodesExceeded},
+		{name: "size exceeded", opts: []Option{WithMaxInputSize(8)}, v1: map[string]any{"key": "value"}, expectErr: ErrInputTooLarge},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDiffer(tt.opts...).DiffValues(ctx, tt.v1, nil)
+			if tt.expectErr != nil {
+				require.ErrorIs(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestDiffer_LimitsOnFiles(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewDiffer(WithMaxInputSize(16)).GetDiff(ctx, fixturePath("nested1.json"), fixturePath("nested2.json"), "")
+	require.ErrorIs(t, err, ErrInputTooLarge)
+
+	_, err = NewDiffer(WithMaxDepth(2)).GetDiff(ctx, fixturePath("nested1.json"), fixturePath("nested2.json"), "")
+	require.ErrorIs(t, err, ErrMaxDepthExceeded)
+
+	_, err = NewDiffer(WithMaxNodes(5)).DiffReaders(ctx, strings.NewReader(`{"a": [1, 2, 3, 4, 5]}`), strings.NewReader("{}"), "json", "json")
+	require.ErrorIs(t, err, ErrMaxNodesExceeded)
+
+	_, err = NewDiffer(WithMaxDepth(4), WithMaxNodes(100), WithMaxInputSize(1<<20)).
+		GetDiff(ctx, fixturePath("nested1.json"), fixturePath("nested2.json"), "")
+	require.NoError(t, err)
+}
+
+func TestDiffer_LimitsWhileParsing(t *testing.T) {
+	ctx := context.Background()
+
+	// Truncated input: the depth limit must be hit before the missing
+	// brackets, and so before any document exists.
+	deep := `{"a": ` + strings.Repeat("[", 1_000_000)
+	_, err := NewDiffer(WithMaxDepth(64)).DiffReaders(ctx, strings.NewReader(deep), strings.NewReader("{}"), "json", "json")
+	require.ErrorIs(t, err, ErrMaxDepthExceeded)
+
+	// Each line doubles the expanded size; the node limit stops the
+	// expansion long before the alias limit of a million nodes would.
+	var bomb strings.Builder
+	bomb.WriteString("a0: &a0 [x, x]\n")
+	for i := 1; i <= 24; i++ {
+		fmt.Fprintf(&bomb, "a%d: &a%d [*a%d, *a%d]\n", i, i, i-1, i-1)
+	}
+	_, err = NewDiffer(WithMaxNodes(10_000)).DiffReaders(ctx, strings.NewReader(bomb.String()), strings.NewReader("{}"), "yaml", "yaml")
+	require.ErrorIs(t, err, ErrMaxNodesExceeded)
+
+	// Likewise for $refs, which are resolved before the limits are checked.
+	var refs strings.Builder
+	refs.WriteString(`{"a0": {"x": 1}`)
+	for i := 1; i <= 40; i++ {
+		fmt.Fprintf(&refs, `, "a%d": {"l": {"$ref": "#/a%d"}, "r": {"$ref": "#/a%d"}}`, i, i-1, i-1)
+	}
+	refs.WriteString("}")
+	_, err = NewDiffer(WithRefResolution(), WithMaxNodes(1000)).DiffReaders(ctx, strings.NewReader(refs.String()), strings.NewReader("{}"), "json", "json")
+	require.ErrorIs(t, err, ErrMaxNodesExceeded)
+
+	_, err = NewDiffer(WithMaxDepth(4), WithMaxNodes(5)).DiffReaders(ctx,
+		strings.NewReader(`{"a": {"b": [1, {"c": 2}]}}`), strings.NewReader("a: {b: [1, {c: 2}]}"), "json", "yaml")
+	require.NoError(t, err)
+
+	_, err = NewDiffer(WithMaxNodes(4)).DiffReaders(ctx,
+		strings.NewReader("{}"), strings.NewReader("a: {b: [1, {c: 2}]}"), "json", "yaml")
+	require.ErrorIs(t, err, ErrMaxNodesExceeded)
+}
+
+func TestDiffer_Cancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	d := NewDiffer()
+
+	_, err := d.GetDiff(ctx, fixturePath("nested1.json"), fixturePath("nested2.json"), "")
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = d.DiffReaders(ctx, strings.NewReader("{}"), strings.NewReader("{}"), "json", "json")
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = d.DiffValues(ctx, nestedObject(3), nestedObject(3))
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = d.getNodes(ctx, "", nestedObject(3), nestedObject(3))
+	require.ErrorIs(t, err, context.Canceled)
+}
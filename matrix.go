@@ -0,0 +1,47 @@
+package code
+
+import (
+	"code/matrix"
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrTooFewFiles = errors.New("at least two files are required")
+
+// Matrix parses the files and compares them all at once, e.g. the same
+// configuration for several environments. Documents are named after their
+// files (see matrix.Names). Limits, schema, comparison rules and redaction
+// apply as in GetDiff.
+func (d *Differ) Matrix(ctx context.Context, paths ...string) (*matrix.Matrix, error) {
+	if len(paths) < 2 {
+		return nil, fmt.Errorf("%w: got %d", ErrTooFewFiles, len(paths))
+	}
+
+	docs := make([]map[string]interface{}, len(paths))
+	for i, path := range paths {
+		if path == "" {
+			return nil, fmt.Errorf("file %d: %w", i+1, ErrEmptyPath)
+		}
+		data, err := d.parseFile(ctx, path, d.warn)
+		if err != nil {
+			return nil, fmt.Errorf("parse file %q: %w", path, err)
+		}
+		docs[i] = data
+	}
+
+	m := matrix.Build(matrix.Names(paths), docs, func(keyPath string, a, b interface{}) bool {
+		equal, _ := d.compare(keyPath, a, b)
+		return equal
+	})
+
+	if d.redactor != nil {
+		for _, row := range m.Rows {
+			for i := range row.Cells {
+				row.Cells[i].Value = d.redactor.Value(row.Path, row.Cells[i].Value)
+			}
+		}
+	}
+
+	return m, nil
+}
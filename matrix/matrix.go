@@ -0,0 +1,167 @@
+// Package matrix compares several documents at once, such as the same
+// configuration in different environments, and reports for each leaf path
+// the value in every document and which documents diverge.
+package matrix
+
+import (
+	"code/internal/utils"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// EqualFunc compares two values found at keyPath.
+type EqualFunc func(keyPath string, a, b interface{}) bool
+
+// Cell is the value of a row in one document.
+type Cell struct {
+	Value interface{}
+	// Missing is set when the document has no value at the path.
+	Missing bool
+	// Divergent is set when the value differs from the one most documents share.
+	Divergent bool
+}
+
+// Row holds one leaf path, with a cell per document in Matrix.Names order.
+type Row struct {
+	Path  string
+	Cells []Cell
+}
+
+// Diverges reports whether any document differs from the others.
+func (r Row) Diverges() bool {
+	for _, c := range r.Cells {
+		if c.Divergent {
+			return true
+		}
+	}
+	return false
+}
+
+// Matrix is the comparison of documents named Names, one row per leaf path
+// sorted by path.
+type Matrix struct {
+	Names []string
+	Rows  []Row
+}
+
+// Build compares docs, named by names, using equal (reflect.DeepEqual when
+// nil). Objects are descended into; arrays and scalars are leaves. The
+// baseline of a row is the value shared by most documents, ties going to the
+// earliest one; documents with another value, or none, are divergent.
+func Build(names []string, docs []map[string]interface{}, equal EqualFunc) *Matrix {
+	if equal == nil {
+		equal = func(_ string, a, b interface{}) bool { return reflect.DeepEqual(a, b) }
+	}
+
+	paths := make(map[string]struct{})
+	for _, doc := range docs {
+		collectPaths("", doc, paths)
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	m := &Matrix{Names: names}
+	for _, p := range sorted {
+		row := Row{Path: p, Cells: make([]Cell, len(docs))}
+		for i, doc := range docs {
+			v, ok := lookup(doc, p)
+			row.Cells[i] = Cell{Value: v, Missing: !ok}
+		}
+		markDivergent(row, equal)
+		m.Rows = append(m.Rows, row)
+	}
+
+	return m
+}
+
+// Divergent returns the matrix restricted to rows where documents differ.
+func (m *Matrix) Divergent() *Matrix {
+	out := &Matrix{Names: m.Names}
+	for _, row := range m.Rows {
+		if row.Diverges() {
+			out.Rows = append(out.Rows, row)
+		}
+	}
+	return out
+}
+
+// Names derives short document names from file paths: the base name without
+// extension, e.g. "prod" for "env/prod.yml". Paths are kept as they are when
+// that would make two names equal.
+func Names(paths []string) []string {
+	names := make([]string, len(paths))
+	seen := make(map[string]bool, len(paths))
+	for i, p := range paths {
+		base := filepath.Base(p)
+		names[i] = strings.TrimSuffix(base, filepath.Ext(base))
+		if seen[names[i]] {
+			return append([]string(nil), paths...)
+		}
+		seen[names[i]] = true
+	}
+	return names
+}
+
+func collectPaths(parentPath string, data map[string]interface{}, paths map[string]struct{}) {
+	for k, v := range data {
+		keyPath := utils.JoinPath(parentPath, k)
+		if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+			collectPaths(keyPath, nested, paths)
+			continue
+		}
+		paths[keyPath] = struct{}{}
+	}
+}
+
+// lookup walks doc along the keys of keyPath, which was built from keys of
+// one of the documents, so it is matched key by key rather than split on dots.
+func lookup(doc map[string]interface{}, keyPath string) (interface{}, bool) {
+	if v, ok := doc[keyPath]; ok {
+		return v, true
+	}
+
+	for k, v := range doc {
+		rest, ok := strings.CutPrefix(keyPath, k+".")
+		if !ok {
+			continue
+		}
+		if nested, ok := v.(map[string]interface{}); ok {
+			if found, ok := lookup(nested, rest); ok {
+				return found, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func markDivergent(row Row, equal EqualFunc) {
+	same := func(a, b Cell) bool {
+		if a.Missing || b.Missing {
+			return a.Missing == b.Missing
+		}
+		return equal(row.Path, a.Value, b.Value)
+	}
+
+	baseline, best := 0, 0
+	for i, c := range row.Cells {
+		count := 0
+		for _, other := range row.Cells {
+			if same(c, other) {
+				count++
+			}
+		}
+		if count > best {
+			baseline, best = i, count
+		}
+	}
+
+	for i := range row.Cells {
+		row.Cells[i].Divergent = !same(row.Cells[baseline], row.Cells[i])
+	}
+}
@@ -0,0 +1,76 @@
+package matrix
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	docs := []map[string]interface{}{
+		{"app": map[string]interface{}{"replicas": 1.0, "debug": true}, "tags": []interface{}{"a"}, "empty": map[string]interface{}{}},
+		{"app": map[string]interface{}{"replicas": 2.0}, "tags": []interface{}{"a"}, "empty": map[string]interface{}{}},
+		{"app": map[string]interface{}{"replicas": 2.0}, "tags": []interface{}{"b"}},
+	}
+
+	m := Build([]string{"dev", "staging", "prod"}, docs, nil)
+
+	require.Equal(t, []string{"dev", "staging", "prod"}, m.Names)
+	require.Equal(t, []Row{
+		{Path: "app.debug", Cells: []Cell{{Value: true, Divergent: true}, {Missing: true}, {Missing: true}}},
+		{Path: "app.replicas", Cells: []Cell{{Value: 1.0, Divergent: true}, {Value: 2.0}, {Value: 2.0}}},
+		{Path: "empty", Cells: []Cell{{Value: map[string]interface{}{}}, {Value: map[string]interface{}{}}, {Missing: true, Divergent: true}}},
+		{Path: "tags", Cells: []Cell{{Value: []interface{}{"a"}}, {Value: []interface{}{"a"}}, {Value: []interface{}{"b"}, Divergent: true}}},
+	}, m.Rows)
+}
+
+func TestBuild_EqualFunc(t *testing.T) {
+	docs := []map[string]interface{}{
+		{"name": "Shop", "port": 80.0},
+		{"name": "shop", "port": 81.0},
+	}
+	ignoreCase := func(_ string, a, b interface{}) bool {
+		sa, ok1 := a.(string)
+		sb, ok2 := b.(string)
+		if ok1 && ok2 {
+			return strings.EqualFold(sa, sb)
+		}
+		return a == b
+	}
+
+	m := Build([]string{"a", "b"}, docs, ignoreCase).Divergent()
+	require.Len(t, m.Rows, 1)
+	require.Equal(t, "port", m.Rows[0].Path)
+	require.True(t, m.Rows[0].Diverges())
+}
+
+func TestBuild_DottedKeys(t *testing.T) {
+	docs := []map[string]interface{}{
+		{"data": map[string]interface{}{"nginx.conf": "a"}},
+		{"data": map[string]interface{}{"nginx.conf": "b"}},
+	}
+
+	m := Build([]string{"a", "b"}, docs, nil)
+	require.Len(t, m.Rows, 1)
+	require.Equal(t, "data.nginx.conf", m.Rows[0].Path)
+	require.Equal(t, []Cell{{Value: "a"}, {Value: "b", Divergent: true}}, m.Rows[0].Cells)
+}
+
+func TestNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		paths    []string
+		expected []string
+	}{
+		{name: "base names", paths: []string{"env/dev.yml", "prod.json"}, expected: []string{"dev", "prod"}},
+		{name: "duplicates", paths: []string{"a/app.yml", "b/app.yml"}, expected: []string{"a/app.yml", "b/app.yml"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, Names(tt.paths))
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package matrix
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats for the matrix.
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+)
+
+const (
+	missingCell     = "-"
+	divergentMarker = " *"
+)
+
+// Write renders m in the given format; an empty format selects the table.
+func Write(w io.Writer, m *Matrix, format string) error {
+	switch format {
+	case "", FormatTable:
+		return WriteTable(w, m)
+	case FormatJSON:
+		return WriteJSON(w, m)
+	default:
+		return fmt.Errorf("unknown matrix format %q: use %s or %s", format, FormatTable, FormatJSON)
+	}
+}
+
+// WriteTable writes one aligned line per row, without a trailing newline.
+// Missing values are shown as "-" and divergent ones are followed by "*".
+func WriteTable(w io.Writer, m *Matrix) error {
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "PATH\t%s\n", strings.Join(m.Names, "\t"))
+	for _, row := range m.Rows {
+		cells := make([]string, len(row.Cells))
+		for i, c := range row.Cells {
+			cells[i] = tableValue(c)
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", row.Path, strings.Join(cells, "\t"))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, strings.TrimSuffix(sb.String(), "\n"))
+	return err
+}
+
+func tableValue(c Cell) string {
+	s := missingCell
+	if !c.Missing {
+		s = formatValue(c.Value)
+	}
+	if c.Divergent {
+		s += divergentMarker
+	}
+	return s
+}
+
+func formatValue(v interface{}) string {
+	if s, ok := v.(string); ok && s != "" && !strings.ContainsAny(s, "\t\n") {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+type jsonMatrix struct {
+	Names []string  `json:"names"`
+	Rows  []jsonRow `json:"rows"`
+}
+
+type jsonRow struct {
+	Path      string                 `json:"path"`
+	Values    map[string]interface{} `json:"values"`
+	Missing   []string               `json:"missing,omitempty"`
+	Divergent []string               `json:"divergent,omitempty"`
+}
+
+// WriteJSON writes the matrix as an indented JSON object with the document
+// names and, per row, the values keyed by name and the names of missing and
+// divergent documents.
+func WriteJSON(w io.Writer, m *Matrix) error {
+	out := jsonMatrix{Names: m.Names, Rows: make([]jsonRow, 0, len(m.Rows))}
+	for _, row := range m.Rows {
+		jr := jsonRow{Path: row.Path, Values: make(map[string]interface{}, len(row.Cells))}
+		for i, c := range row.Cells {
+			name := m.Names[i]
+			if c.Missing {
+				jr.Missing = append(jr.Missing, name)
+			} else {
+				jr.Values[name] = c.Value
+			}
+			if c.Divergent {
+				jr.Divergent = append(jr.Divergent, name)
+			}
+		}
+		out.Rows = append(out.Rows, jr)
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,69 @@
+package matrix
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testMatrix() *Matrix {
+	return &Matrix{
+		Names: []string{"dev", "prod"},
+		Rows: []Row{
+			{Path: "debug", Cells: []Cell{{Value: true}, {Missing: true, Divergent: true}}},
+			{Path: "host", Cells: []Cell{{Value: "localhost"}, {Value: "db.prod", Divergent: true}}},
+			{Path: "note", Cells: []Cell{{Value: ""}, {Value: ""}}},
+		},
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, WriteTable(&sb, testMatrix()))
+
+	require.Equal(t, strings.Join([]string{
+		"PATH   dev        prod",
+		"debug  true       - *",
+		"host   localhost  db.prod *",
+		`note   ""         ""`,
+	}, "\n"), sb.String())
+}
+
+func TestWriteJSON(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, WriteJSON(&sb, &Matrix{Names: testMatrix().Names, Rows: testMatrix().Rows[:1]}))
+
+	require.JSONEq(t, `{
+		"names": ["dev", "prod"],
+		"rows": [{"path": "debug", "values": {"dev": true}, "missing": ["prod"], "divergent": ["prod"]}]
+	}`, sb.String())
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    string
+		prefix    string
+		expectErr bool
+	}{
+		{name: "default", format: "", prefix: "PATH"},
+		{name: "table", format: FormatTable, prefix: "PATH"},
+		{name: "json", format: FormatJSON, prefix: "{"},
+		{name: "unknown", format: "xml", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			err := Write(&sb, testMatrix(), tt.format)
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(sb.String(), tt.prefix))
+		})
+	}
+}
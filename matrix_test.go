@@ -0,0 +1,46 @@
+package code
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"code/matrix"
+	"code/redact"
+
+	"github.com/stretchr/testify/require"
+)
+
+func envPaths() []string {
+	return []string{fixturePath("envs/dev.yml"), fixturePath("envs/staging.yml"), fixturePath("envs/prod.yml")}
+}
+
+func TestDiffer_Matrix(t *testing.T) {
+	m, err := NewDiffer().Matrix(context.Background(), envPaths()...)
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	require.NoError(t, matrix.WriteTable(&sb, m.Divergent()))
+	require.Equal(t, readExpected(t, "matrix_table.txt"), sb.String())
+	require.Len(t, m.Rows, 5)
+}
+
+func TestDiffer_MatrixOptions(t *testing.T) {
+	differ := NewDiffer(WithRedaction(redact.Rule{Key: "host"}), WithRedactionKey([]byte("k")))
+
+	m, err := differ.Matrix(context.Background(), envPaths()...)
+	require.NoError(t, err)
+	require.Equal(t, "db.host", m.Rows[3].Path)
+	require.Equal(t, redact.NewWithKey([]byte("k")).Marker("localhost"), m.Rows[3].Cells[0].Value)
+}
+
+func TestDiffer_MatrixErrors(t *testing.T) {
+	_, err := NewDiffer().Matrix(context.Background(), fixturePath("envs/dev.yml"))
+	require.ErrorIs(t, err, ErrTooFewFiles)
+
+	_, err = NewDiffer().Matrix(context.Background(), fixturePath("envs/dev.yml"), "")
+	require.ErrorIs(t, err, ErrEmptyPath)
+
+	_, err = NewDiffer().Matrix(context.Background(), fixturePath("envs/dev.yml"), fixturePath("nonexistent.yml"))
+	require.Error(t, err)
+}
@@ -0,0 +1,204 @@
+// Package merge combines configuration layers, such as a base file and an
+// environment override, into the effective configuration the way Helm values
+// and Spring profiles are applied: later layers win, objects are merged key
+// by key and lists follow a configurable strategy.
+package merge
+
+import (
+	"code/internal/utils"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidRule = errors.New("invalid list merge rule")
+
+// Lists selects how a list from a later layer combines with the list at the
+// same path in an earlier one.
+type Lists string
+
+const (
+	// Replace uses the later list as is. It is the default.
+	Replace Lists = "replace"
+	// Append adds the elements of the later list after the earlier ones.
+	Append Lists = "append"
+	// Index merges elements at the same position and keeps the extra
+	// elements of the longer list.
+	Index Lists = "index"
+	// ByKey merges objects whose Rule.Key fields are equal, e.g. Kubernetes
+	// containers by "name", and appends the others.
+	ByKey Lists = "key"
+)
+
+// Rule applies a list strategy to lists whose dotted path matches Pattern,
+// with the syntax of compare.Rule patterns. Lists inside list elements have
+// the path of the outer list followed by their key, without an index, e.g.
+// "spec.containers.ports". An empty pattern matches every list.
+type Rule struct {
+	Pattern string
+	Lists   Lists
+	// Key is the field that identifies elements for ByKey.
+	Key string
+}
+
+// ParseRule reads a rule written as "strategy" or "pattern=strategy", where
+// strategy is replace, append, index or key:field.
+func ParseRule(s string) (Rule, error) {
+	var rule Rule
+	strategy := s
+	if pattern, rest, ok := strings.Cut(s, "="); ok {
+		rule.Pattern, strategy = strings.TrimSpace(pattern), rest
+	}
+	strategy = strings.TrimSpace(strategy)
+
+	if key, ok := strings.CutPrefix(strategy, "key:"); ok {
+		if key == "" {
+			return Rule{}, fmt.Errorf("%w: %q: key: needs a field name", ErrInvalidRule, s)
+		}
+		rule.Lists, rule.Key = ByKey, key
+		return rule, nil
+	}
+
+	switch Lists(strategy) {
+	case Replace, Append, Index:
+		rule.Lists = Lists(strategy)
+		return rule, nil
+	}
+	return Rule{}, fmt.Errorf("%w: %q: expected replace, append, index or key:field", ErrInvalidRule, s)
+}
+
+// Merger merges layers using the last rule that matches each list.
+type Merger struct {
+	rules []Rule
+}
+
+func New(rules ...Rule) *Merger {
+	return &Merger{rules: rules}
+}
+
+// Merge returns the layers merged in order. A later value replaces an
+// earlier one unless both are objects, which are merged, or both are lists,
+// which are combined by the matching rule. The layers are not modified; nil
+// layers are skipped.
+func (m *Merger) Merge(layers ...map[string]interface{}) map[string]interface{} {
+	var result map[string]interface{}
+	for _, layer := range layers {
+		if layer == nil {
+			continue
+		}
+		if result == nil {
+			result = copyValue(layer).(map[string]interface{})
+			continue
+		}
+		m.mergeObject(result, layer, "")
+	}
+	return result
+}
+
+// mergeObject merges src into dst, which it owns.
+func (m *Merger) mergeObject(dst, src map[string]interface{}, path string) {
+	for key, value := range src {
+		dst[key] = m.mergeValue(dst[key], value, utils.JoinPath(path, key))
+	}
+}
+
+// mergeValue returns src merged over dst, which it owns.
+func (m *Merger) mergeValue(dst, src interface{}, path string) interface{} {
+	switch s := src.(type) {
+	case map[string]interface{}:
+		if d, ok := dst.(map[string]interface{}); ok {
+			m.mergeObject(d, s, path)
+			return d
+		}
+	case []interface{}:
+		if d, ok := dst.([]interface{}); ok {
+			return m.mergeList(d, s, path)
+		}
+	}
+	return copyValue(src)
+}
+
+func (m *Merger) mergeList(dst, src []interface{}, path string) []interface{} {
+	rule := m.ruleFor(path)
+	switch rule.Lists {
+	case Append:
+		for _, v := range src {
+			dst = append(dst, copyValue(v))
+		}
+		return dst
+
+	case Index:
+		for i, v := range src {
+			if i < len(dst) {
+				dst[i] = m.mergeValue(dst[i], v, path)
+			} else {
+				dst = append(dst, copyValue(v))
+			}
+		}
+		return dst
+
+	case ByKey:
+		positions := make(map[string]int)
+		for i, v := range dst {
+			if id, ok := elementKey(v, rule.Key); ok {
+				positions[id] = i
+			}
+		}
+		for _, v := range src {
+			id, ok := elementKey(v, rule.Key)
+			if i, found := positions[id]; ok && found {
+				dst[i] = m.mergeValue(dst[i], v, path)
+				continue
+			}
+			if ok {
+				positions[id] = len(dst)
+			}
+			dst = append(dst, copyValue(v))
+		}
+		return dst
+	}
+
+	return copyValue(src).([]interface{})
+}
+
+func (m *Merger) ruleFor(path string) Rule {
+	rule := Rule{Lists: Replace}
+	for _, r := range m.rules {
+		if utils.MatchPath(r.Pattern, path) {
+			rule = r
+		}
+	}
+	return rule
+}
+
+// elementKey returns the identity of a list element for ByKey: the key field
+// of an object, formatted so that 1 and "1" stay distinct.
+func elementKey(v interface{}, key string) (string, bool) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	id, ok := obj[key]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%T:%v", id, id), true
+}
+
+func copyValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(val))
+		for key, child := range val {
+			result[key] = copyValue(child)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(val))
+		for i, child := range val {
+			result[i] = copyValue(child)
+		}
+		return result
+	}
+	return v
+}
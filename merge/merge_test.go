@@ -0,0 +1,134 @@
+package merge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  Rule
+		expectErr bool
+	}{
+		{name: "strategy", input: "append", expected: Rule{Lists: Append}},
+		{name: "pattern", input: "**.args=index", expected: Rule{Pattern: "**.args", Lists: Index}},
+		{name: "key", input: "spec.containers = key:name", expected: Rule{Pattern: "spec.containers", Lists: ByKey, Key: "name"}},
+		{name: "replace", input: "replace", expected: Rule{Lists: Replace}},
+		{name: "unknown strategy", input: "prepend", expectErr: true},
+		{name: "key without field", input: "key:", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRule(tt.input)
+			if tt.expectErr {
+				require.ErrorIs(t, err, ErrInvalidRule)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, rule)
+		})
+	}
+}
+
+func TestMerger_Merge(t *testing.T) {
+	base := map[string]interface{}{
+		"image": map[string]interface{}{"repository": "api", "tag": "1.0"},
+		"args":  []interface{}{"--port=80", "--verbose"},
+		"containers": []interface{}{
+			map[string]interface{}{"name": "app", "cpu": "100m", "ports": []interface{}{80.0}},
+			map[string]interface{}{"name": "sidecar", "cpu": "50m"},
+		},
+		"replicas": 1.0,
+	}
+	override := map[string]interface{}{
+		"image": map[string]interface{}{"tag": "1.1"},
+		"args":  []interface{}{"--port=8080"},
+		"containers": []interface{}{
+			map[string]interface{}{"name": "app", "cpu": "500m", "ports": []interface{}{443.0}},
+			map[string]interface{}{"name": "proxy"},
+		},
+		"replicas": map[string]interface{}{"min": 2.0},
+	}
+
+	tests := []struct {
+		name     string
+		rules    []Rule
+		expected map[string]interface{}
+	}{
+		{
+			name: "replace lists by default",
+			expected: map[string]interface{}{
+				"image": map[string]interface{}{"repository": "api", "tag": "1.1"},
+				"args":  []interface{}{"--port=8080"},
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "cpu": "500m", "ports": []interface{}{443.0}},
+					map[string]interface{}{"name": "proxy"},
+				},
+				"replicas": map[string]interface{}{"min": 2.0},
+			},
+		},
+		{
+			name:  "append",
+			rules: []Rule{{Lists: Append}},
+			expected: map[string]interface{}{
+				"image": map[string]interface{}{"repository": "api", "tag": "1.1"},
+				"args":  []interface{}{"--port=80", "--verbose", "--port=8080"},
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "cpu": "100m", "ports": []interface{}{80.0}},
+					map[string]interface{}{"name": "sidecar", "cpu": "50m"},
+					map[string]interface{}{"name": "app", "cpu": "500m", "ports": []interface{}{443.0}},
+					map[string]interface{}{"name": "proxy"},
+				},
+				"replicas": map[string]interface{}{"min": 2.0},
+			},
+		},
+		{
+			name:  "index",
+			rules: []Rule{{Lists: Index}},
+			expected: map[string]interface{}{
+				"image": map[string]interface{}{"repository": "api", "tag": "1.1"},
+				"args":  []interface{}{"--port=8080", "--verbose"},
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "cpu": "500m", "ports": []interface{}{443.0}},
+					map[string]interface{}{"name": "proxy", "cpu": "50m"},
+				},
+				"replicas": map[string]interface{}{"min": 2.0},
+			},
+		},
+		{
+			name: "by key with a nested rule",
+			rules: []Rule{
+				{Pattern: "containers", Lists: ByKey, Key: "name"},
+				{Pattern: "containers.ports", Lists: Append},
+			},
+			expected: map[string]interface{}{
+				"image": map[string]interface{}{"repository": "api", "tag": "1.1"},
+				"args":  []interface{}{"--port=8080"},
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "cpu": "500m", "ports": []interface{}{80.0, 443.0}},
+					map[string]interface{}{"name": "sidecar", "cpu": "50m"},
+					map[string]interface{}{"name": "proxy"},
+				},
+				"replicas": map[string]interface{}{"min": 2.0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result := New(tt.rules...).Merge(base, nil, override)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+
+	require.Equal(t, "1.0", base["image"].(map[string]interface{})["tag"])
+	require.Len(t, base["args"], 2)
+	require.Nil(t, New().Merge(nil, nil))
+}
@@ -0,0 +1,345 @@
+package code
+
+import (
+	"code/diff"
+	"code/internal/utils"
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// maxMoveCandidates bounds the candidate pairs kept for each removed subtree,
+// so that pairing stays linear in the number of subtrees.
+const maxMoveCandidates = 8
+
+// WithMoveDetection reports a subtree that was removed at one path and added
+// at another as a single moved or renamed node. Subtrees match when their
+// similarity (the share of identical leaf values) is at least threshold;
+// a threshold outside (0, 1] means only identical subtrees match.
+func WithMoveDetection(threshold float64) Option {
+	return func(d *Differ) {
+		if threshold <= 0 || threshold > 1 {
+			threshold = 1
+		}
+		d.moveThreshold = threshold
+	}
+}
+
+// subtree is an object found in a removed or added value.
+type subtree struct {
+	// order is the position of the subtree among those collected.
+	order  int
+	path   []string
+	value  map[string]interface{}
+	leaves map[string]interface{}
+}
+
+type movePair struct {
+	from, to   *subtree
+	similarity float64
+}
+
+// detectMoves rewrites matching removed/added subtrees in nodes into moved
+// and renamed nodes.
+func (d *Differ) detectMoves(ctx context.Context, nodes []*diff.Node) ([]*diff.Node, error) {
+	var removed, added []*subtree
+	collectSubtrees(nodes, nil, &removed, &added)
+	if len(removed) == 0 || len(added) == 0 {
+		return nodes, nil
+	}
+
+	rw := &moveRewriter{split: make(map[*diff.Node]diff.NodeType)}
+
+	pairs, err := matchSubtrees(ctx, removed, added, d.moveThreshold)
+	if err != nil {
+		return nil, err
+	}
+	for _, pair := range pairs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		moved := &diff.Node{
+			Type: diff.NodeTypeMoved,
+			Key:  pair.to.path[len(pair.to.path)-1],
+			From: strings.Join(pair.from.path, "."),
+		}
+		if sameParent(pair.from.path, pair.to.path) {
+			moved.Type = diff.NodeTypeRenamed
+		}
+
+		if reflect.DeepEqual(pair.from.value, pair.to.value) {
+			moved.Value = pair.to.value
+		} else {
+			children, err := d.getNodes(ctx, strings.Join(pair.to.path, "."), pair.from.value, pair.to.value)
+			if err != nil {
+				return nil, err
+			}
+			moved.Children = children
+		}
+
+		nodes = rw.replace(nodes, pair.from.path, nil)
+		nodes = rw.replace(nodes, pair.to.path, moved)
+	}
+
+	return nodes, nil
+}
+
+// collectSubtrees gathers every object inside removed and added nodes,
+// including objects nested in them.
+func collectSubtrees(nodes []*diff.Node, parent []string, removed, added *[]*subtree) {
+	for _, node := range nodes {
+		path := appendPath(parent, node.Key)
+
+		switch node.Type {
+		case diff.NodeTypeRemoved:
+			collectObjects(node.Value, path, removed)
+		case diff.NodeTypeAdded:
+			collectObjects(node.Value, path, added)
+		case diff.NodeTypeNested:
+			collectSubtrees(node.Children, path, removed, added)
+		}
+	}
+}
+
+func collectObjects(v interface{}, path []string, out *[]*subtree) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	leaves := make(map[string]interface{})
+	flattenLeaves(m, "", leaves)
+	if len(leaves) == 0 {
+		return
+	}
+	*out = append(*out, &subtree{order: len(*out), path: path, value: m, leaves: leaves})
+
+	for _, key := range utils.SortedKeys(m) {
+		collectObjects(m[key], appendPath(path, key), out)
+	}
+}
+
+func flattenLeaves(m map[string]interface{}, prefix string, leaves map[string]interface{}) {
+	for k, v := range m {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+
+		if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenLeaves(nested, path, leaves)
+			continue
+		}
+		leaves[path] = v
+	}
+}
+
+// matchSubtrees pairs removed and added subtrees greedily, preferring the most
+// similar and then the largest pairs, so that a moved parent wins over its
+// children. Pairs never overlap on either side.
+//
+// A pair can only reach threshold if the added subtree shares one of the
+// rarest leaves of the removed one, so only those leaves are looked up in an
+// index of leaf values, and at most maxMoveCandidates pairs are kept per
+// removed subtree.
+func matchSubtrees(ctx context.Context, removed, added []*subtree, threshold float64) ([]movePair, error) {
+	index := make(map[string][]int)
+	for i, to := range added {
+		for path, v := range to.leaves {
+			key := leafKey(path, v)
+			index[key] = append(index[key], i)
+		}
+	}
+
+	var candidates []movePair
+	for _, from := range removed {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		postings := make([][]int, 0, len(from.leaves))
+		for path, v := range from.leaves {
+			postings = append(postings, index[leafKey(path, v)])
+		}
+		sort.Slice(postings, func(i, j int) bool { return len(postings[i]) < len(postings[j]) })
+
+		seen := make(map[int]bool)
+		var best []movePair
+		for _, posting := range postings[:prefixLength(len(from.leaves), threshold)] {
+			for _, i := range posting {
+				if seen[i] {
+					continue
+				}
+				seen[i] = true
+				to := added[i]
+				if s := similarity(from.leaves, to.leaves, threshold); s >= threshold {
+					best = append(best, movePair{from: from, to: to, similarity: s})
+				}
+			}
+		}
+		sort.SliceStable(best, func(i, j int) bool { return best[i].to.order < best[j].to.order })
+		sortMovePairs(best)
+		if len(best) > maxMoveCandidates {
+			best = best[:maxMoveCandidates]
+		}
+		candidates = append(candidates, best...)
+	}
+	sortMovePairs(candidates)
+
+	var pairs []movePair
+	for _, c := range candidates {
+		if overlapsAny(c.from.path, pairs, true) || overlapsAny(c.to.path, pairs, false) {
+			continue
+		}
+		pairs = append(pairs, c)
+	}
+
+	return pairs, nil
+}
+
+func sortMovePairs(pairs []movePair) {
+	sort.SliceStable(pairs, func(i, j int) bool {
+		a, b := pairs[i], pairs[j]
+		if a.similarity != b.similarity {
+			return a.similarity > b.similarity
+		}
+		return len(a.from.leaves)+len(a.to.leaves) > len(b.from.leaves)+len(b.to.leaves)
+	})
+}
+
+// prefixLength returns how many of the n leaves of a subtree must be looked
+// up to find every subtree with a similarity of at least threshold: a match
+// shares at least ceil(n*threshold/(2-threshold)) leaves with it.
+func prefixLength(n int, threshold float64) int {
+	overlap := int(math.Ceil(float64(n)*threshold/(2-threshold) - 1e-9))
+	if overlap < 1 {
+		overlap = 1
+	}
+	return n - overlap + 1
+}
+
+// leafKey identifies a leaf by its path and value; equal values print alike.
+func leafKey(path string, v interface{}) string {
+	return fmt.Sprintf("%s=%#v", path, v)
+}
+
+// similarity returns the Dice coefficient of two leaf sets, or 0 when it
+// cannot reach threshold.
+func similarity(a, b map[string]interface{}, threshold float64) float64 {
+	total := len(a) + len(b)
+	smaller := len(a)
+	if len(b) < smaller {
+		smaller = len(b)
+	}
+	if float64(2*smaller)/float64(total) < threshold {
+		return 0
+	}
+
+	matches := 0
+	for path, v := range a {
+		if other, ok := b[path]; ok && reflect.DeepEqual(v, other) {
+			matches++
+		}
+	}
+
+	return float64(2*matches) / float64(total)
+}
+
+func overlapsAny(path []string, pairs []movePair, fromSide bool) bool {
+	for _, p := range pairs {
+		other := p.to.path
+		if fromSide {
+			other = p.from.path
+		}
+		if hasPrefix(path, other) || hasPrefix(other, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// moveRewriter edits the diff tree in place of matched subtrees. It remembers
+// which nested nodes it created by splitting added or removed objects.
+type moveRewriter struct {
+	split map[*diff.Node]diff.NodeType
+}
+
+// replace replaces the node at path with replacement, or deletes it when
+// replacement is nil. Added and removed objects on the way are split into
+// nested nodes so that the rest of their content keeps its original type.
+func (rw *moveRewriter) replace(nodes []*diff.Node, path []string, replacement *diff.Node) []*diff.Node {
+	for i, node := range nodes {
+		if node.Key != path[0] {
+			continue
+		}
+
+		if len(path) == 1 {
+			if replacement == nil {
+				return append(nodes[:i:i], nodes[i+1:]...)
+			}
+			nodes[i] = replacement
+			return nodes
+		}
+
+		if node.Type == diff.NodeTypeAdded || node.Type == diff.NodeTypeRemoved {
+			node = rw.splitObject(node)
+		}
+		node.Children = rw.replace(node.Children, path[1:], replacement)
+		nodes[i] = rw.collapseEmpty(node)
+		return nodes
+	}
+
+	return nodes
+}
+
+// splitObject turns an added or removed object into a nested node whose
+// children are added or removed one by one.
+func (rw *moveRewriter) splitObject(node *diff.Node) *diff.Node {
+	m, _ := node.Value.(map[string]interface{})
+
+	children := make([]*diff.Node, 0, len(m))
+	for _, key := range utils.SortedKeys(m) {
+		children = append(children, &diff.Node{Type: node.Type, Key: key, Value: m[key]})
+	}
+
+	split := &diff.Node{Type: diff.NodeTypeNested, Key: node.Key, Children: children}
+	rw.split[split] = node.Type
+	return split
+}
+
+// collapseEmpty turns a split object whose content was fully moved away back
+// into an added or removed empty object.
+func (rw *moveRewriter) collapseEmpty(node *diff.Node) *diff.Node {
+	originalType, ok := rw.split[node]
+	if !ok || len(node.Children) > 0 {
+		return node
+	}
+	return &diff.Node{Type: originalType, Key: node.Key, Value: map[string]interface{}{}}
+}
+
+func appendPath(parent []string, key string) []string {
+	path := make([]string, len(parent)+1)
+	copy(path, parent)
+	path[len(parent)] = key
+	return path
+}
+
+func hasPrefix(path, prefix []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameParent(a, b []string) bool {
+	return len(a) == len(b) && hasPrefix(a, b[:len(b)-1])
+}
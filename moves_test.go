@@ -0,0 +1,133 @@
+package code
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"code/diff"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffer_MoveDetection(t *testing.T) {
+	differ := NewDiffer(WithMoveDetection(0.5))
+
+	for _, format := range []string{"stylish", "plain", "json"} {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			result, err := differ.GetDiff(context.Background(), fixturePath("moves1.yml"), fixturePath("moves2.yml"), format)
+			require.NoError(t, err)
+			require.Equal(t, readExpected(t, "moves_"+format+".txt"), result)
+		})
+	}
+}
+
+func TestDiffer_detectMoves(t *testing.T) {
+	ctx := context.Background()
+	db := map[string]any{"host": "localhost", "port": 5432}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		v1, v2    map[string]any
+		expected  []*diff.Node
+	}{
+		{
+			name:      "identical only by default",
+			threshold: 0,
+			v1:        map[string]any{"a": map[string]any{"x": 1, "y": 2}},
+			v2:        map[string]any{"b": map[string]any{"x": 1, "y": 3}},
+			expected: []*diff.Node{
+				{Type: diff.NodeTypeRemoved, Key: "a", Value: map[string]interface{}{"x": 1.0, "y": 2.0}},
+				{Type: diff.NodeTypeAdded, Key: "b", Value: map[string]interface{}{"x": 1.0, "y": 3.0}},
+			},
+		},
+		{
+			name:      "moved out of removed parent",
+			threshold: 1,
+			v1:        map[string]any{"old": map[string]any{"db": db}},
+			v2:        map[string]any{"db": db},
+			expected: []*diff.Node{
+				{Type: diff.NodeTypeMoved, Key: "db", From: "old.db", Value: map[string]interface{}{"host": "localhost", "port": 5432.0}},
+				{Type: diff.NodeTypeRemoved, Key: "old", Value: map[string]interface{}{}},
+			},
+		},
+		{
+			name:      "renamed next to kept siblings",
+			threshold: 1,
+			v1:        map[string]any{"cfg": map[string]any{"database": db, "name": "svc"}},
+			v2:        map[string]any{"cfg": map[string]any{"db": db, "name": "svc"}},
+			expected: []*diff.Node{
+				{Type: diff.NodeTypeNested, Key: "cfg", Children: []*diff.Node{
+					{Type: diff.NodeTypeRenamed, Key: "db", From: "cfg.database", Value: map[string]interface{}{"host": "localhost", "port": 5432.0}},
+					{Type: diff.NodeTypeUnchanged, Key: "name", Value: "svc"},
+				}},
+			},
+		},
+		{
+			name:      "parent match wins over child match",
+			threshold: 1,
+			v1:        map[string]any{"a": map[string]any{"inner": db}},
+			v2:        map[string]any{"b": map[string]any{"inner": db}},
+			expected: []*diff.Node{
+				{Type: diff.NodeTypeRenamed, Key: "b", From: "a", Value: map[string]interface{}{"inner": map[string]interface{}{"host": "localhost", "port": 5432.0}}},
+			},
+		},
+		{
+			name:      "scalars are not moved",
+			threshold: 1,
+			v1:        map[string]any{"a": 1},
+			v2:        map[string]any{"b": 1},
+			expected: []*diff.Node{
+				{Type: diff.NodeTypeRemoved, Key: "a", Value: 1.0},
+				{Type: diff.NodeTypeAdded, Key: "b", Value: 1.0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := NewDiffer(WithMoveDetection(tt.threshold)).DiffValues(ctx, tt.v1, tt.v2)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, nodes)
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	a := map[string]interface{}{"x": 1.0, "y": 2.0}
+	b := map[string]interface{}{"x": 1.0, "y": 3.0}
+	c := map[string]interface{}{"x": 1.0, "y": 2.0, "z": 3.0, "w": 4.0, "v": 5.0, "u": 6.0}
+
+	require.Equal(t, 1.0, similarity(a, a, 1))
+	require.Equal(t, 0.5, similarity(a, b, 0.5))
+	require.Equal(t, 0.0, similarity(a, c, 0.9))
+	require.Equal(t, 0.5, similarity(a, c, 0.5))
+}
+
+func TestDiffer_detectMovesManySubtrees(t *testing.T) {
+	v1, v2 := map[string]any{}, map[string]any{}
+	for i := 0; i < 2000; i++ {
+		v1[fmt.Sprintf("old%d", i)] = map[string]any{"enabled": true, "id": i}
+		v2[fmt.Sprintf("new%d", i)] = map[string]any{"enabled": true, "id": i}
+	}
+
+	nodes, err := NewDiffer(WithMoveDetection(0.9)).DiffValues(context.Background(), v1, v2)
+	require.NoError(t, err)
+	require.Len(t, nodes, 2000)
+	for _, node := range nodes {
+		require.Equal(t, diff.NodeTypeRenamed, node.Type)
+		require.Equal(t, "old"+strings.TrimPrefix(node.Key, "new"), node.From)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d := NewDiffer(WithMoveDetection(0.9))
+	nodes, err = d.getNodes(context.Background(), "", v1, v2)
+	require.NoError(t, err)
+	_, err = d.detectMoves(ctx, nodes)
+	require.ErrorIs(t, err, context.Canceled)
+}
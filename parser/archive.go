@@ -0,0 +1,222 @@
+package parser
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"code/diff"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	ErrUnsupportedCompression = errors.New("unsupported compression")
+	ErrNestedArchive          = errors.New("archive nested too deeply")
+)
+
+const (
+	// MaxArchiveMembers limits the number of members read from an archive,
+	// including the members of archives nested in it.
+	MaxArchiveMembers = 10000
+	// MaxArchiveDepth limits how deeply archives may be nested, counting
+	// the outermost one.
+	MaxArchiveDepth = 2
+)
+
+// maxZstdWindow caps the window a .zst frame may ask the decoder to
+// allocate, as the zstd tool does by default.
+const maxZstdWindow = 128 << 20
+
+// Decompressor returns a reader of the decompressed content of r.
+type Decompressor func(r io.Reader) (io.Reader, error)
+
+// Gzip decompresses .gz input.
+func Gzip(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
+
+// Bzip2 decompresses .bz2 input.
+func Bzip2(r io.Reader) (io.Reader, error) {
+	return bzip2.NewReader(r), nil
+}
+
+// Zstd decompresses .zst input.
+func Zstd(r io.Reader) (io.Reader, error) {
+	d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(maxZstdWindow))
+	if err != nil {
+		return nil, err
+	}
+	return d.IOReadCloser(), nil
+}
+
+// AddDecompressor registers d for the given extensions. Files ending in one
+// of them are decompressed and then parsed by their inner extension, so
+// config.json.gz is read as JSON.
+func (r *FileParser) AddDecompressor(d Decompressor, exts ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, ext := range exts {
+		ext = strings.ToLower(ext)
+		if _, exists := r.decompressors[ext]; !exists {
+			r.compressedFormats = append(r.compressedFormats, ext)
+		}
+		r.decompressors[ext] = d
+	}
+}
+
+func decompress(ctx context.Context, d Decompressor, data []byte, opts ParseOptions) ([]byte, error) {
+	rd, err := d(bytes.NewReader(data))
+	if err != nil {
+		if errors.Is(err, ErrUnsupportedCompression) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %w", ErrReadFile, err)
+	}
+	if c, ok := rd.(io.Closer); ok {
+		defer c.Close()
+	}
+	return opts.archive.read(ctx, rd, opts.MaxSize)
+}
+
+// readBudget is shared by the parts of an input that are read after it,
+// such as the members of an archive and of archives nested in it, or
+// included files, so that ParseOptions.MaxSize bounds their total size.
+// members counts archive members.
+type readBudget struct {
+	remaining int64
+	members   int
+}
+
+// enterArchive returns opts for the members of an archive, starting the
+// budget at the outermost archive.
+func enterArchive(opts ParseOptions) (ParseOptions, error) {
+	if opts.archiveDepth >= MaxArchiveDepth {
+		return opts, fmt.Errorf("%w: more than %d levels", ErrNestedArchive, MaxArchiveDepth)
+	}
+	if opts.archive == nil {
+		opts.archive = &readBudget{remaining: opts.MaxSize}
+	}
+	opts.archiveDepth++
+	return opts, nil
+}
+
+// read is readAll for a part of an input, charging what it reads to the
+// budget. Without a budget it applies maxSize alone.
+func (b *readBudget) read(ctx context.Context, rd io.Reader, maxSize int64) ([]byte, error) {
+	if b == nil || maxSize <= 0 {
+		return readAll(ctx, rd, maxSize)
+	}
+
+	data, err := readAll(ctx, rd, max(b.remaining, 0)+1)
+	if errors.Is(err, ErrInputTooLarge) || err == nil && int64(len(data)) > b.remaining {
+		return nil, fmt.Errorf("%w of %d bytes in total", ErrInputTooLarge, maxSize)
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.remaining -= int64(len(data))
+	return data, nil
+}
+
+// parseTar parses every supported regular file of a tar archive. The result
+// is keyed by member path, so two archives are compared member by member.
+func (r *FileParser) parseTar(ctx context.Context, data []byte, opts ParseOptions) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: tar: %w", ErrReadFile, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err := r.addMember(ctx, result, hdr.Name, tr, opts); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseZip is parseTar for zip archives.
+func (r *FileParser) parseZip(ctx context.Context, data []byte, opts ParseOptions) (map[string]interface{}, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: zip: %w", ErrReadFile, err)
+	}
+
+	result := make(map[string]interface{})
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%w: zip: %s: %w", ErrReadFile, f.Name, err)
+		}
+		err = r.addMember(ctx, result, f.Name, rc, opts)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// addMember parses an archive member into result. Members without a
+// registered parser, such as READMEs, are skipped.
+func (r *FileParser) addMember(ctx context.Context, result map[string]interface{}, name string, rd io.Reader, opts ParseOptions) error {
+	name = strings.TrimPrefix(path.Clean(filepath.ToSlash(name)), "/")
+	if strings.HasPrefix(name, "__MACOSX/") || !r.supports(name) {
+		return nil
+	}
+
+	opts.archive.members++
+	if opts.archive.members > MaxArchiveMembers {
+		return fmt.Errorf("%w: more than %d archive members", ErrInputTooLarge, MaxArchiveMembers)
+	}
+
+	data, err := opts.archive.read(ctx, rd, opts.MaxSize)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, name)
+	}
+	if warn := opts.Warn; warn != nil {
+		opts.Warn = func(w diff.Warning) {
+			w.Source = path.Join(name, w.Source)
+			warn(w)
+		}
+	}
+	doc, err := r.decode(ctx, name, "", data, opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	result[name] = doc
+	return nil
+}
+
+// supports reports whether a file called name can be decoded.
+func (r *FileParser) supports(name string) bool {
+	for {
+		ext := strings.ToLower(filepath.Ext(name))
+		if _, ok := r.lookupDecompressor(ext); !ok {
+			_, ok := r.lookup(ext)
+			return ok || ext == ".tar" || ext == ".zip"
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+}
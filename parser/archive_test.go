@@ -0,0 +1,263 @@
+package parser
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"code/diff"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+// bzip2JSON is `{"a": 1}` compressed with bzip2 -9; the standard library
+// has no bzip2 writer.
+const bzip2JSON = "425a6839314159265359d64d6a790000031980500020102000000a20002218021804e27d6e177245385090d64d6a79"
+
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func zstdBytes(t *testing.T, data string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw, err := zstd.NewWriter(&buf)
+	require.NoError(t, err)
+	_, err = zw.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func tarBytes(t *testing.T, members map[string][]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "conf/", Typeflag: tar.TypeDir, Mode: 0o755}))
+	for _, name := range sortedNames(members) {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(members[name]))}))
+		_, err := tw.Write(members[name])
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func zipBytes(t *testing.T, members map[string][]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	_, err := zw.Create("conf/")
+	require.NoError(t, err)
+	for _, name := range sortedNames(members) {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(members[name])
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func sortedNames(members map[string][]byte) []string {
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func archiveParser() *FileParser {
+	fp := NewFileParser()
+	fp.Add(&JSONParser{}, ".json")
+	fp.Add(&YAMLParser{}, ".yml")
+	fp.AddDecompressor(Gzip, ".gz")
+	fp.AddDecompressor(Bzip2, ".bz2")
+	fp.AddDecompressor(Zstd, ".zst")
+	return fp
+}
+
+func TestFileParser_Decompress(t *testing.T) {
+	bz2, err := hex.DecodeString(bzip2JSON)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		data      []byte
+		format    string
+		maxSize   int64
+		expected  map[string]interface{}
+		expectErr error
+	}{
+		{name: "gzip", data: gzipBytes(t, "a: 1"), format: "yml.gz", expected: map[string]interface{}{"a": 1.0}},
+		{name: "bzip2", data: bz2, format: ".json.BZ2", expected: map[string]interface{}{"a": 1.0}},
+		{name: "gzip twice", data: gzipBytes(t, string(gzipBytes(t, `{"a": 1}`))), format: "json.gz.gz", expected: map[string]interface{}{"a": 1.0}},
+		{name: "corrupt gzip", data: []byte("not gzip"), format: "json.gz", expectErr: ErrReadFile},
+		{name: "unknown inner format", data: gzipBytes(t, "a = 1"), format: "toml.gz", expectErr: ErrUnsupportedFormat},
+		{name: "zstd", data: zstdBytes(t, `{"a": 1}`), format: "json.zst", expected: map[string]interface{}{"a": 1.0}},
+		{name: "corrupt zstd", data: []byte{0x28, 0xb5, 0x2f, 0xfd}, format: "json.zst", expectErr: ErrReadFile},
+		{name: "decompressed size limit", data: gzipBytes(t, `{"a": "`+string(bytes.Repeat([]byte("x"), 1000))+`"}`), format: "json.gz", maxSize: 100, expectErr: ErrInputTooLarge},
+		{name: "decompressed zstd size limit", data: zstdBytes(t, `{"a": "`+string(bytes.Repeat([]byte("x"), 1000))+`"}`), format: "json.zst", maxSize: 100, expectErr: ErrInputTooLarge},
+	}
+
+	fp := archiveParser()
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := fp.ParseReader(context.Background(), bytes.NewReader(tt.data), tt.format, ParseOptions{MaxSize: tt.maxSize})
+			if tt.expectErr != nil {
+				require.ErrorIs(t, err, tt.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestFileParser_UnsupportedFormatListsCompression(t *testing.T) {
+	_, err := archiveParser().ParseReader(context.Background(), bytes.NewReader(nil), "toml", ParseOptions{})
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+	require.Contains(t, err.Error(), "compressed: .gz, .bz2, .zst")
+}
+
+func TestFileParser_RegisteredDecompressor(t *testing.T) {
+	fp := archiveParser()
+	other := NewFileParser()
+	other.AddDecompressor(func(r io.Reader) (io.Reader, error) { return r, nil }, ".zst")
+	fp.Extend(other)
+
+	result, err := fp.ParseReader(context.Background(), bytes.NewReader([]byte(`{"a": 1}`)), "json.zst", ParseOptions{})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"a": 1.0}, result)
+}
+
+func TestFileParser_Archives(t *testing.T) {
+	members := map[string][]byte{
+		"conf/app.json":       []byte(`{"port": 8080}`),
+		"./conf/db.yml.gz":    gzipBytes(t, "host: localhost"),
+		"conf/README.md":      []byte("# not parsed"),
+		"__MACOSX/._app.json": {0x00, 0x05},
+	}
+	expected := map[string]interface{}{
+		"conf/app.json":  map[string]interface{}{"port": 8080.0},
+		"conf/db.yml.gz": map[string]interface{}{"host": "localhost"},
+	}
+
+	tests := []struct {
+		name     string
+		fileName string
+		data     []byte
+	}{
+		{name: "tar", fileName: "snapshot.tar", data: tarBytes(t, members)},
+		{name: "compressed tar", fileName: "snapshot.tar.gz", data: gzipBytes(t, string(tarBytes(t, members)))},
+		{name: "zip", fileName: "snapshot.zip", data: zipBytes(t, members)},
+	}
+
+	fp := archiveParser()
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.fileName)
+			require.NoError(t, os.WriteFile(path, tt.data, 0o600))
+
+			result, err := fp.ParseFile(context.Background(), path, ParseOptions{})
+			require.NoError(t, err)
+			require.Equal(t, expected, result)
+		})
+	}
+}
+
+func TestFileParser_ArchiveMemberError(t *testing.T) {
+	fp := archiveParser()
+	data := zipBytes(t, map[string][]byte{"bad.json": []byte("{")})
+
+	_, err := fp.ParseReader(context.Background(), bytes.NewReader(data), "zip", ParseOptions{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "bad.json")
+
+	_, err = fp.ParseReader(context.Background(), bytes.NewReader([]byte("not a zip")), "zip", ParseOptions{})
+	require.ErrorIs(t, err, ErrReadFile)
+}
+
+func TestFileParser_ArchiveWarnings(t *testing.T) {
+	fp := archiveParser()
+	data := zipBytes(t, map[string][]byte{"conf/app.json": []byte(`{"a": 1, "a": 2}`)})
+
+	var warnings []diff.Warning
+	_, err := fp.ParseReader(context.Background(), bytes.NewReader(data), "zip", ParseOptions{
+		Warn: func(w diff.Warning) { warnings = append(warnings, w) },
+	})
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	require.Equal(t, "conf/app.json", warnings[0].Source)
+}
+
+func TestFileParser_ArchiveTotalSize(t *testing.T) {
+	fp := archiveParser()
+	member := []byte(`{"a": "` + strings.Repeat("x", 40) + `"}`)
+	data := tarBytes(t, map[string][]byte{"a.json": member, "b.json": member, "c.json": member})
+
+	result, err := fp.ParseReader(context.Background(), bytes.NewReader(data), "tar", ParseOptions{MaxSize: int64(len(data))})
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+
+	// The archive and each member fit, but not all members together.
+	large := []byte(`{"a": "` + strings.Repeat("x", 2000) + `"}`)
+	zipped := zipBytes(t, map[string][]byte{"a.json": large, "b.json": large, "c.json": large})
+	require.Less(t, len(zipped), 3000)
+	_, err = fp.ParseReader(context.Background(), bytes.NewReader(zipped), "zip", ParseOptions{MaxSize: 3000})
+	require.ErrorIs(t, err, ErrInputTooLarge)
+	require.Contains(t, err.Error(), "in total")
+}
+
+func TestFileParser_ArchiveMemberCount(t *testing.T) {
+	fp := archiveParser()
+	members := make(map[string][]byte, MaxArchiveMembers+1)
+	for i := 0; i <= MaxArchiveMembers; i++ {
+		members[fmt.Sprintf("m%05d.json", i)] = []byte("{}")
+	}
+
+	_, err := fp.ParseReader(context.Background(), bytes.NewReader(tarBytes(t, members)), "tar", ParseOptions{})
+	require.ErrorIs(t, err, ErrInputTooLarge)
+	require.Contains(t, err.Error(), "archive members")
+}
+
+func TestFileParser_NestedArchives(t *testing.T) {
+	fp := archiveParser()
+	inner := zipBytes(t, map[string][]byte{"app.json": []byte(`{"a": 1}`)})
+	outer := zipBytes(t, map[string][]byte{"inner.zip": inner})
+
+	result, err := fp.ParseReader(context.Background(), bytes.NewReader(outer), "zip", ParseOptions{})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"inner.zip": map[string]interface{}{"app.json": map[string]interface{}{"a": 1.0}},
+	}, result)
+
+	tooDeep := tarBytes(t, map[string][]byte{"outer.zip": outer})
+	_, err = fp.ParseReader(context.Background(), bytes.NewReader(tooDeep), "tar", ParseOptions{})
+	require.ErrorIs(t, err, ErrNestedArchive)
+}
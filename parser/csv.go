@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CSVParser reads comma- or tab-separated tables with a header row. Each row
+// becomes an object of its cells keyed by header name, and rows are keyed
+// by the value of the Key column (the first column when Key is empty), so
+// rows can be matched across files regardless of their order. Values are
+// strings.
+type CSVParser struct {
+	Key   string
+	Comma rune
+}
+
+func (p *CSVParser) Parse(data []byte) (map[string]interface{}, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\uFEFF"))))
+	if p.Comma != 0 {
+		r.Comma = p.Comma
+	}
+	if r.Comma == '\t' {
+		r.LazyQuotes = true
+	}
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("parse csv: header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+	keyColumn, err := p.keyColumn(header)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for {
+		record, err := r.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return result, nil
+			}
+			return nil, fmt.Errorf("parse csv: %w", err)
+		}
+
+		line, _ := r.FieldPos(keyColumn)
+		key := record[keyColumn]
+		if key == "" {
+			return nil, fmt.Errorf("parse csv: line %d: empty %q", line, header[keyColumn])
+		}
+		if _, exists := result[key]; exists {
+			return nil, fmt.Errorf("parse csv: line %d: duplicate %q %q", line, header[keyColumn], key)
+		}
+
+		row := make(map[string]interface{}, len(header)-1)
+		for i, name := range header {
+			if i != keyColumn {
+				row[name] = record[i]
+			}
+		}
+		result[key] = row
+	}
+}
+
+func (p *CSVParser) keyColumn(header []string) (int, error) {
+	seen := make(map[string]bool, len(header))
+	for _, name := range header {
+		if seen[name] {
+			return 0, fmt.Errorf("parse csv: duplicate column %q", name)
+		}
+		seen[name] = true
+	}
+
+	if p.Key == "" {
+		return 0, nil
+	}
+	for i, name := range header {
+		if name == p.Key {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("parse csv: key column %q not found in header", p.Key)
+}
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVParser_Parse(t *testing.T) {
+	tests := []struct {
+		name      string
+		parser    CSVParser
+		data      string
+		expected  map[string]interface{}
+		expectErr bool
+	}{
+		{
+			name:   "keyed by first column",
+			parser: CSVParser{},
+			data:   "flag,enabled,rollout\nnew_ui,true,50\n\"checkout, v2\",false,0\n",
+			expected: map[string]interface{}{
+				"new_ui":       map[string]interface{}{"enabled": "true", "rollout": "50"},
+				"checkout, v2": map[string]interface{}{"enabled": "false", "rollout": "0"},
+			},
+		},
+		{
+			name:   "keyed by named column",
+			parser: CSVParser{Key: "id"},
+			data:   "\uFEFFplan, id ,price\nBasic,1,10\r\nPro,2,25\r\n",
+			expected: map[string]interface{}{
+				"1": map[string]interface{}{"plan": "Basic", "price": "10"},
+				"2": map[string]interface{}{"plan": "Pro", "price": "25"},
+			},
+		},
+		{
+			name:   "tab separated",
+			parser: CSVParser{Key: "sku", Comma: '\t'},
+			data:   "sku\tname\nA-1\t12\" pipe\n",
+			expected: map[string]interface{}{
+				"A-1": map[string]interface{}{"name": `12" pipe`},
+			},
+		},
+		{
+			name:     "header only",
+			parser:   CSVParser{},
+			data:     "id,name\n",
+			expected: map[string]interface{}{},
+		},
+		{
+			name:      "empty input",
+			parser:    CSVParser{},
+			data:      "",
+			expectErr: true,
+		},
+		{
+			name:      "unknown key column",
+			parser:    CSVParser{Key: "sku"},
+			data:      "id,name\n1,a\n",
+			expectErr: true,
+		},
+		{
+			name:      "duplicate key",
+			parser:    CSVParser{},
+			data:      "id,name\n1,a\n1,b\n",
+			expectErr: true,
+		},
+		{
+			name:      "empty key",
+			parser:    CSVParser{},
+			data:      "id,name\n,a\n",
+			expectErr: true,
+		},
+		{
+			name:      "duplicate column",
+			parser:    CSVParser{},
+			data:      "id,name,name\n1,a,b\n",
+			expectErr: true,
+		},
+		{
+			name:      "wrong number of fields",
+			parser:    CSVParser{},
+			data:      "id,name\n1,a,b\n",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.parser.Parse([]byte(tt.data))
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
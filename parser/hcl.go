@@ -0,0 +1,662 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// HCLParser reads HashiCorp Configuration Language files such as Terraform
+// .tf and .tfvars. Attributes become keys; blocks are nested under their
+// type and then each label, so `resource "aws_instance" "web" { ... }` is
+// found at resource.aws_instance.web. Repeated blocks with the same type and
+// labels become arrays. Literal values (strings, numbers, booleans, null,
+// tuples and objects) keep their type; other expressions such as references
+// and function calls are kept as their source text in "${...}" form, the way
+// Terraform's JSON syntax writes them. Escapes in strings, including $${
+// and %%{, are decoded.
+type HCLParser struct{}
+
+func (p *HCLParser) Parse(data []byte) (map[string]interface{}, error) {
+	return p.ParseContext(context.Background(), "", data, ParseOptions{})
+}
+
+// ParseContext is Parse with the depth and node limits of opts enforced
+// while blocks, tuples and objects are read.
+func (p *HCLParser) ParseContext(ctx context.Context, _ string, data []byte, opts ParseOptions) (map[string]interface{}, error) {
+	s := &hclScanner{src: []rune(string(data)), limits: newLimiter(ctx, opts)}
+	body := make(map[string]interface{})
+	if err := s.parseBody(body, 0); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+type hclScanner struct {
+	src    []rune
+	pos    int
+	limits *limiter
+	// level is the nesting depth of the body, tuple or object being read.
+	level int
+}
+
+func (s *hclScanner) errorf(format string, args ...interface{}) error {
+	line := 1 + strings.Count(string(s.src[:min(s.pos, len(s.src))]), "\n")
+	return fmt.Errorf("parse hcl: line %d: %w", line, fmt.Errorf(format, args...))
+}
+
+// value accounts for a value read one level below the current one.
+func (s *hclScanner) value() error {
+	if err := s.limits.nested(s.level + 1); err != nil {
+		return s.errorf("%w", err)
+	}
+	return nil
+}
+
+func (s *hclScanner) eof() bool {
+	return s.pos >= len(s.src)
+}
+
+func (s *hclScanner) peek() rune {
+	if s.eof() {
+		return 0
+	}
+	return s.src[s.pos]
+}
+
+func (s *hclScanner) hasPrefix(prefix string) bool {
+	rs := []rune(prefix)
+	return s.pos+len(rs) <= len(s.src) && string(s.src[s.pos:s.pos+len(rs)]) == prefix
+}
+
+func (s *hclScanner) atComment() bool {
+	return s.peek() == '#' || s.hasPrefix("//") || s.hasPrefix("/*")
+}
+
+// skipComment skips one comment; line comments stop before the newline.
+func (s *hclScanner) skipComment() error {
+	if s.hasPrefix("/*") {
+		for i := s.pos + 2; i+1 < len(s.src); i++ {
+			if s.src[i] == '*' && s.src[i+1] == '/' {
+				s.pos = i + 2
+				return nil
+			}
+		}
+		return s.errorf("unterminated comment")
+	}
+	for !s.eof() && s.peek() != '\n' {
+		s.pos++
+	}
+	return nil
+}
+
+// skipSpace skips blanks and comments, and newlines too when newlines is set.
+func (s *hclScanner) skipSpace(newlines bool) error {
+	for !s.eof() {
+		r := s.peek()
+		switch {
+		case r == '\n' && !newlines:
+			return nil
+		case r == ' ' || r == '\t' || r == '\r' || r == '\n' || r == '\uFEFF':
+			s.pos++
+		case s.hasPrefix("/*") || ((r == '#' || s.hasPrefix("//")) && newlines):
+			if err := s.skipComment(); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func isHCLIdentRune(r rune, first bool) bool {
+	if unicode.IsLetter(r) || r == '_' {
+		return true
+	}
+	return !first && (unicode.IsDigit(r) || r == '-')
+}
+
+func (s *hclScanner) parseIdent() string {
+	start := s.pos
+	for !s.eof() && isHCLIdentRune(s.peek(), s.pos == start) {
+		s.pos++
+	}
+	return string(s.src[start:s.pos])
+}
+
+// parseBody reads attributes and blocks into body until end (0 for end of input).
+func (s *hclScanner) parseBody(body map[string]interface{}, end rune) error {
+	blocks := make(map[string]int)
+
+	for {
+		if err := s.skipSpace(true); err != nil {
+			return err
+		}
+		if s.eof() {
+			if end != 0 {
+				return s.errorf("expected %q before end of input", end)
+			}
+			return nil
+		}
+		if end != 0 && s.peek() == end {
+			s.pos++
+			return nil
+		}
+
+		name := s.parseIdent()
+		if name == "" {
+			return s.errorf("expected an attribute or block name, got %q", s.peek())
+		}
+		if err := s.skipSpace(false); err != nil {
+			return err
+		}
+
+		if s.peek() == '=' && !s.hasPrefix("==") {
+			s.pos++
+			if _, exists := body[name]; exists {
+				return s.errorf("duplicate attribute %q", name)
+			}
+			if err := s.value(); err != nil {
+				return err
+			}
+			value, err := s.parseExpr()
+			if err != nil {
+				return err
+			}
+			body[name] = value
+		} else if err := s.parseBlock(body, blocks, name); err != nil {
+			return err
+		}
+
+		if err := s.skipSpace(false); err != nil {
+			return err
+		}
+		if s.atComment() {
+			if err := s.skipComment(); err != nil {
+				return err
+			}
+		}
+		if !s.eof() && s.peek() != '\n' && s.peek() != end {
+			return s.errorf("unexpected %q after %q", s.peek(), name)
+		}
+	}
+}
+
+// parseBlock reads the labels and body of a block and stores the body under
+// its type and labels. blocks counts the bodies stored per path in body.
+func (s *hclScanner) parseBlock(body map[string]interface{}, blocks map[string]int, blockType string) error {
+	path := []string{blockType}
+	for s.peek() != '{' {
+		switch {
+		case s.peek() == '"':
+			label, err := s.parseString()
+			if err != nil {
+				return err
+			}
+			path = append(path, label)
+		case isHCLIdentRune(s.peek(), true):
+			path = append(path, s.parseIdent())
+		default:
+			return s.errorf("expected '=' or a block after %q", blockType)
+		}
+		if err := s.skipSpace(false); err != nil {
+			return err
+		}
+	}
+	s.pos++
+
+	// The body is nested under the type and each label.
+	for range path {
+		if err := s.value(); err != nil {
+			return err
+		}
+		s.level++
+	}
+	blockBody := make(map[string]interface{})
+	err := s.parseBody(blockBody, '}')
+	s.level -= len(path)
+	if err != nil {
+		return err
+	}
+
+	parent := body
+	for _, key := range path[:len(path)-1] {
+		switch child := parent[key].(type) {
+		case nil:
+			next := make(map[string]interface{})
+			parent[key] = next
+			parent = next
+		case map[string]interface{}:
+			parent = child
+		default:
+			return s.errorf("block %q conflicts with attribute %q", strings.Join(path, " "), key)
+		}
+	}
+
+	key := path[len(path)-1]
+	id := strings.Join(path, "\x00")
+	existing, exists := parent[key]
+	switch {
+	case !exists:
+		parent[key] = blockBody
+	case blocks[id] == 1:
+		parent[key] = []interface{}{existing, blockBody}
+	case blocks[id] > 1:
+		parent[key] = append(existing.([]interface{}), blockBody)
+	default:
+		return s.errorf("block %q conflicts with an attribute or block of the same name", strings.Join(path, " "))
+	}
+	blocks[id]++
+	return nil
+}
+
+// parseExpr reads a literal value, or the source text of any other
+// expression as "${...}".
+func (s *hclScanner) parseExpr() (interface{}, error) {
+	if err := s.skipSpace(false); err != nil {
+		return nil, err
+	}
+
+	start := s.pos
+	value, ok, err := s.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if err := s.skipSpace(false); err != nil {
+			return nil, err
+		}
+		if s.atExprEnd() {
+			return value, nil
+		}
+	}
+
+	s.pos = start
+	raw, err := s.scanRawExpr()
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		return nil, s.errorf("expected a value")
+	}
+	return "${" + raw + "}", nil
+}
+
+func (s *hclScanner) atExprEnd() bool {
+	if s.eof() || s.atComment() {
+		return true
+	}
+	return strings.ContainsRune("\n,}])", s.peek())
+}
+
+// parseLiteral reads a literal; ok is false when the expression is not one.
+func (s *hclScanner) parseLiteral() (interface{}, bool, error) {
+	r := s.peek()
+	switch {
+	case r == '"':
+		str, err := s.parseString()
+		return str, err == nil, err
+
+	case s.hasPrefix("<<"):
+		str, err := s.parseHeredoc()
+		return str, err == nil, err
+
+	case r == '[':
+		if s.startsFor() {
+			return nil, false, nil
+		}
+		return s.parseTuple()
+
+	case r == '{':
+		if s.startsFor() {
+			return nil, false, nil
+		}
+		return s.parseObject()
+
+	case r == '-' || unicode.IsDigit(r):
+		start := s.pos
+		s.pos++
+		for !s.eof() && (unicode.IsDigit(s.peek()) || strings.ContainsRune(".eE", s.peek()) ||
+			(strings.ContainsRune("+-", s.peek()) && strings.ContainsRune("eE", s.src[s.pos-1]))) {
+			s.pos++
+		}
+		n, err := strconv.ParseFloat(string(s.src[start:s.pos]), 64)
+		return n, err == nil, nil
+
+	case isHCLIdentRune(r, true):
+		switch s.parseIdent() {
+		case "true":
+			return true, true, nil
+		case "false":
+			return false, true, nil
+		case "null":
+			return nil, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// startsFor reports whether the bracket at the current position opens a
+// for expression.
+func (s *hclScanner) startsFor() bool {
+	i := s.pos + 1
+	for i < len(s.src) && unicode.IsSpace(s.src[i]) {
+		i++
+	}
+	rest := string(s.src[i:min(i+4, len(s.src))])
+	return rest == "for " || rest == "for\t"
+}
+
+func (s *hclScanner) parseTuple() (interface{}, bool, error) {
+	s.pos++
+	s.level++
+	defer func() { s.level-- }()
+	items := []interface{}{}
+	for {
+		if err := s.skipSpace(true); err != nil {
+			return nil, false, err
+		}
+		if s.peek() == ']' {
+			s.pos++
+			return items, true, nil
+		}
+		if s.eof() {
+			return nil, false, s.errorf("expected ']' before end of input")
+		}
+
+		if err := s.value(); err != nil {
+			return nil, false, err
+		}
+		item, err := s.parseExpr()
+		if err != nil {
+			return nil, false, err
+		}
+		items = append(items, item)
+
+		if err := s.skipSpace(true); err != nil {
+			return nil, false, err
+		}
+		if s.peek() == ',' {
+			s.pos++
+		} else if s.peek() != ']' {
+			return nil, false, s.errorf("expected ',' or ']' in tuple")
+		}
+	}
+}
+
+func (s *hclScanner) parseObject() (interface{}, bool, error) {
+	s.pos++
+	s.level++
+	defer func() { s.level-- }()
+	obj := make(map[string]interface{})
+	for {
+		if err := s.skipSpace(true); err != nil {
+			return nil, false, err
+		}
+		if s.peek() == '}' {
+			s.pos++
+			return obj, true, nil
+		}
+		if s.eof() {
+			return nil, false, s.errorf("expected '}' before end of input")
+		}
+
+		var key string
+		switch {
+		case s.peek() == '"':
+			k, err := s.parseString()
+			if err != nil {
+				return nil, false, err
+			}
+			key = k
+		case isHCLIdentRune(s.peek(), true):
+			key = s.parseIdent()
+		default:
+			raw, err := s.scanBalanced()
+			if err != nil {
+				return nil, false, err
+			}
+			key = "${" + raw + "}"
+		}
+
+		if err := s.skipSpace(false); err != nil {
+			return nil, false, err
+		}
+		if s.peek() != '=' && s.peek() != ':' {
+			return nil, false, s.errorf("expected '=' or ':' after object key %q", key)
+		}
+		s.pos++
+
+		if err := s.value(); err != nil {
+			return nil, false, err
+		}
+		value, err := s.parseExpr()
+		if err != nil {
+			return nil, false, err
+		}
+		obj[key] = value
+
+		if err := s.skipSpace(false); err != nil {
+			return nil, false, err
+		}
+		if s.peek() == ',' {
+			s.pos++
+		}
+	}
+}
+
+// parseString reads a quoted template string. Escapes, including $${ and
+// %%{ for literal ${ and %{, are decoded; interpolations are kept as written.
+func (s *hclScanner) parseString() (string, error) {
+	s.pos++
+	var sb strings.Builder
+	for {
+		if s.eof() || s.peek() == '\n' {
+			return "", s.errorf("unterminated string")
+		}
+
+		r := s.peek()
+		switch {
+		case r == '"':
+			s.pos++
+			return sb.String(), nil
+
+		case r == '\\':
+			decoded, err := s.parseEscape()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteRune(decoded)
+
+		case s.hasPrefix("$${") || s.hasPrefix("%%{"):
+			sb.WriteRune(r)
+			sb.WriteRune('{')
+			s.pos += 3
+
+		case s.hasPrefix("${") || s.hasPrefix("%{"):
+			start := s.pos
+			s.pos++
+			if _, err := s.scanBalanced(); err != nil {
+				return "", err
+			}
+			sb.WriteString(string(s.src[start:s.pos]))
+
+		default:
+			sb.WriteRune(r)
+			s.pos++
+		}
+	}
+}
+
+// parseEscape decodes the escape sequence at the current position: \n, \r,
+// \t, \", \\, \uNNNN or \UNNNNNNNN.
+func (s *hclScanner) parseEscape() (rune, error) {
+	start := s.pos
+	s.pos += 2
+	if s.pos > len(s.src) {
+		return 0, s.errorf("unterminated string")
+	}
+
+	switch s.src[s.pos-1] {
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case '"':
+		return '"', nil
+	case '\\':
+		return '\\', nil
+	case 'u', 'U':
+		n := 4
+		if s.src[s.pos-1] == 'U' {
+			n = 8
+		}
+		if s.pos+n > len(s.src) {
+			return 0, s.errorf("invalid escape %q", string(s.src[start:]))
+		}
+		s.pos += n
+		code, err := strconv.ParseUint(string(s.src[s.pos-n:s.pos]), 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return 0, s.errorf("invalid escape %q", string(s.src[start:s.pos]))
+		}
+		return rune(code), nil
+	}
+	return 0, s.errorf("invalid escape %q", string(s.src[start:s.pos]))
+}
+
+// hclTemplateEscapes decodes the $${ and %%{ escapes of heredoc templates.
+var hclTemplateEscapes = strings.NewReplacer("$${", "${", "%%{", "%{")
+
+// parseHeredoc reads <<MARKER or <<-MARKER text. The indented form strips
+// the smallest common indentation; $${ and %%{ are decoded as in strings.
+func (s *hclScanner) parseHeredoc() (string, error) {
+	s.pos += 2
+	indented := s.peek() == '-'
+	if indented {
+		s.pos++
+	}
+	marker := s.parseIdent()
+	if s.peek() == '\r' {
+		s.pos++
+	}
+	if marker == "" || s.peek() != '\n' {
+		return "", s.errorf("expected a heredoc marker followed by a newline")
+	}
+	s.pos++
+
+	var lines []string
+	for {
+		if s.eof() {
+			return "", s.errorf("heredoc %q is not terminated", marker)
+		}
+		start := s.pos
+		for !s.eof() && s.peek() != '\n' {
+			s.pos++
+		}
+		line := strings.TrimSuffix(string(s.src[start:s.pos]), "\r")
+		if strings.TrimSpace(line) == marker {
+			break
+		}
+		lines = append(lines, line)
+		if !s.eof() {
+			s.pos++
+		}
+	}
+
+	if indented {
+		lines = hclDedent(lines)
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return hclTemplateEscapes.Replace(strings.Join(lines, "\n")) + "\n", nil
+}
+
+func hclDedent(lines []string) []string {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			out[i] = line[indent:]
+		} else {
+			out[i] = strings.TrimLeft(line, " \t")
+		}
+	}
+	return out
+}
+
+// scanBalanced skips a bracketed expression starting at the current
+// position, including nested brackets and strings, and returns its text
+// without the outer brackets.
+func (s *hclScanner) scanBalanced() (string, error) {
+	open := s.peek()
+	closing := map[rune]rune{'(': ')', '[': ']', '{': '}'}[open]
+	if closing == 0 {
+		return "", s.errorf("unexpected %q", open)
+	}
+	s.level++
+	defer func() { s.level-- }()
+	if s.level > maxNesting {
+		return "", s.errorf("%w: limit %d", ErrMaxDepthExceeded, maxNesting)
+	}
+
+	start := s.pos
+	s.pos++
+	for {
+		if s.eof() {
+			return "", s.errorf("expected %q before end of input", closing)
+		}
+		switch r := s.peek(); {
+		case r == closing:
+			s.pos++
+			return string(s.src[start+1 : s.pos-1]), nil
+		case r == '"':
+			if _, err := s.parseString(); err != nil {
+				return "", err
+			}
+		case r == '(' || r == '[' || r == '{':
+			if _, err := s.scanBalanced(); err != nil {
+				return "", err
+			}
+		default:
+			s.pos++
+		}
+	}
+}
+
+// scanRawExpr returns the source text of an expression, which ends at a
+// newline, comma, comment or closing bracket outside nested brackets.
+func (s *hclScanner) scanRawExpr() (string, error) {
+	start := s.pos
+	for !s.eof() && !s.atExprEnd() {
+		switch s.peek() {
+		case '"':
+			if _, err := s.parseString(); err != nil {
+				return "", err
+			}
+		case '(', '[', '{':
+			if _, err := s.scanBalanced(); err != nil {
+				return "", err
+			}
+		default:
+			s.pos++
+		}
+	}
+	return strings.TrimSpace(string(s.src[start:s.pos])), nil
+}
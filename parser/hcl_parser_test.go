@@ -0,0 +1,258 @@
+package parser
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHCLParser_Parse(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		expected  map[string]interface{}
+		expectErr bool
+	}{
+		{
+			name: "attributes",
+			data: `
+# comment
+region   = "eu-west-1" // trailing comment
+replicas = 3
+ratio    = 0.5
+enabled  = true
+owner    = null
+/* block
+   comment */
+zones = [
+  "a",
+  "b", # second
+]
+tags = { env = "prod", "cost-center": 42 }
+escaped = "a\tb \"c\" ${var.x}"
+`,
+			expected: map[string]interface{}{
+				"region":   "eu-west-1",
+				"replicas": 3.0,
+				"ratio":    0.5,
+				"enabled":  true,
+				"owner":    nil,
+				"zones":    []interface{}{"a", "b"},
+				"tags":     map[string]interface{}{"env": "prod", "cost-center": 42.0},
+				"escaped":  "a\tb \"c\" ${var.x}",
+			},
+		},
+		{
+			name: "blocks",
+			data: `
+terraform {
+  required_version = ">= 1.5"
+}
+
+resource "aws_instance" "web" {
+  ami = "ami-1"
+
+  ebs_block_device {
+    size = 10
+  }
+  ebs_block_device {
+    size = 20
+  }
+}
+
+resource "aws_instance" "db" { ami = "ami-2" }
+`,
+			expected: map[string]interface{}{
+				"terraform": map[string]interface{}{"required_version": ">= 1.5"},
+				"resource": map[string]interface{}{
+					"aws_instance": map[string]interface{}{
+						"web": map[string]interface{}{
+							"ami": "ami-1",
+							"ebs_block_device": []interface{}{
+								map[string]interface{}{"size": 10.0},
+								map[string]interface{}{"size": 20.0},
+							},
+						},
+						"db": map[string]interface{}{"ami": "ami-2"},
+					},
+				},
+			},
+		},
+		{
+			name: "expressions",
+			data: `
+ami      = var.ami
+name     = "${var.prefix}-web"
+count    = var.enabled ? 1 : 0
+subnets  = [for s in var.subnets : s.id]
+cidr     = cidrsubnet(var.cidr, 8, 1)
+refs     = [var.a, local.b]
+`,
+			expected: map[string]interface{}{
+				"ami":     "${var.ami}",
+				"name":    "${var.prefix}-web",
+				"count":   "${var.enabled ? 1 : 0}",
+				"subnets": "${[for s in var.subnets : s.id]}",
+				"cidr":    "${cidrsubnet(var.cidr, 8, 1)}",
+				"refs":    []interface{}{"${var.a}", "${local.b}"},
+			},
+		},
+		{
+			name: "heredocs",
+			data: "policy = <<EOF\n{\n  \"a\": 1\n}\nEOF\nscript = <<-EOT\n    echo a\n      echo b\n    EOT\n",
+			expected: map[string]interface{}{
+				"policy": "{\n  \"a\": 1\n}\n",
+				"script": "echo a\n  echo b\n",
+			},
+		},
+		{
+			name: "heredocs with CRLF line endings",
+			data: "policy = <<EOF\r\n{\r\n  \"a\": 1\r\n}\r\nEOF\r\nscript = <<-EOT\r\n    echo a\r\n    EOT\r\nname = \"x\"\r\n",
+			expected: map[string]interface{}{
+				"policy": "{\n  \"a\": 1\n}\n",
+				"script": "echo a\n",
+				"name":   "x",
+			},
+		},
+		{
+			name:      "duplicate attribute",
+			data:      "a = 1\na = 2\n",
+			expectErr: true,
+		},
+		{
+			name:      "block conflicts with attribute",
+			data:      "a = 1\na { b = 2 }\n",
+			expectErr: true,
+		},
+		{
+			name:      "unterminated block",
+			data:      "a {\n b = 1\n",
+			expectErr: true,
+		},
+		{
+			name:      "unterminated string",
+			data:      "a = \"b\n",
+			expectErr: true,
+		},
+		{
+			name:      "missing value",
+			data:      "a = \n",
+			expectErr: true,
+		},
+		{
+			name: "escapes",
+			data: `a = "\U0001F600 \u00e9 \\ \r\n"
+b = "$${var.x} %%{if} ${var.y}"
+c = <<EOT
+$${var.x}
+EOT
+`,
+			expected: map[string]interface{}{
+				"a": "\U0001F600 \u00e9 \\ \r\n",
+				"b": "${var.x} %{if} ${var.y}",
+				"c": "${var.x}\n",
+			},
+		},
+		{
+			name:      "invalid escape",
+			data:      `a = "\q"`,
+			expectErr: true,
+		},
+		{
+			name:      "truncated unicode escape",
+			data:      `a = "\U0001F6"`,
+			expectErr: true,
+		},
+		{
+			name:     "many comments",
+			data:     strings.Repeat("/* c */\n", 1000) + "a = 1 /* c */\n",
+			expected: map[string]interface{}{"a": 1.0},
+		},
+		{
+			name:      "unterminated comment",
+			data:      "a = 1 /* c *",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p := &HCLParser{}
+			result, err := p.Parse([]byte(tt.data))
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestHCLParser_Limits(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		data   string
+		opts   ParseOptions
+		target error
+	}{
+		{
+			name:   "nested tuples",
+			data:   "a = " + strings.Repeat("[", 6<<20),
+			opts:   ParseOptions{MaxDepth: 50},
+			target: ErrMaxDepthExceeded,
+		},
+		{
+			name:   "nested objects without limits",
+			data:   "a = " + strings.Repeat("{b = ", 1<<20),
+			target: ErrMaxDepthExceeded,
+		},
+		{
+			name:   "nested expressions without limits",
+			data:   "a = f(" + strings.Repeat("(", 1<<20),
+			target: ErrMaxDepthExceeded,
+		},
+		{
+			name:   "nested blocks",
+			data:   "a \"b\" {\n c {\n d = 1\n }\n}\n",
+			opts:   ParseOptions{MaxDepth: 3},
+			target: ErrMaxDepthExceeded,
+		},
+		{
+			name:   "nodes",
+			data:   "a = [" + strings.Repeat("1, ", 1000) + "]",
+			opts:   ParseOptions{MaxNodes: 1000},
+			target: ErrMaxNodesExceeded,
+		},
+		{
+			name:   "cancelled",
+			ctx:    cancelled,
+			data:   "a = [" + strings.Repeat("1, ", 2000) + "]",
+			target: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			_, err := (&HCLParser{}).ParseContext(ctx, "", []byte(tt.data), tt.opts)
+			require.ErrorIs(t, err, tt.target)
+		})
+	}
+
+	result, err := (&HCLParser{}).ParseContext(context.Background(), "", []byte("a \"b\" {\n c = [1]\n}\n"), ParseOptions{MaxDepth: 4, MaxNodes: 4})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"b": map[string]interface{}{"c": []interface{}{1.0}}}, result["a"])
+}
@@ -0,0 +1,971 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// maxIncludeDepth bounds nested includes, which also stops include cycles.
+const maxIncludeDepth = 32
+
+// maxHOCONSubstNodes and maxHOCONSubstText bound the values copied and the
+// text concatenated while resolving substitutions, so that a small document
+// cannot expand into an enormous one.
+const (
+	maxHOCONSubstNodes = 1_000_000
+	maxHOCONSubstText  = 16 << 20
+)
+
+// HOCONParser reads HOCON files: objects with optional root braces,
+// "=" / ":" separators, comments, unquoted and triple-quoted strings, value
+// concatenation, duplicate keys merging objects, "+=" appends, substitutions
+// (${path}, ${?path}) and file includes relative to the including file.
+// Includes must stay in the directory of the input; url() and classpath()
+// includes are not supported. Substitutions missing from the document fall
+// back to LookupEnv when it is set, e.g. to os.LookupEnv.
+type HOCONParser struct {
+	LookupEnv func(name string) (string, bool)
+}
+
+func (p *HOCONParser) Parse(data []byte) (map[string]interface{}, error) {
+	return p.ParsePath("", data)
+}
+
+func (p *HOCONParser) ParsePath(path string, data []byte) (map[string]interface{}, error) {
+	return p.ParseContext(context.Background(), path, data, ParseOptions{})
+}
+
+// ParseContext is ParsePath with includes read through NewIncludes, so that
+// they share the size limit of opts, and with the depth and node limits of
+// opts enforced both on the document as written and on the values that
+// substitutions expand to.
+func (p *HOCONParser) ParseContext(ctx context.Context, path string, data []byte, opts ParseOptions) (map[string]interface{}, error) {
+	doc := &hoconDocument{includes: NewIncludes(ctx, path, opts), limits: newLimiter(ctx, opts)}
+	root, err := doc.parse(path, data, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &hoconResolver{
+		ctx:       ctx,
+		root:      root,
+		resolving: make(map[string]bool),
+		resolved:  make(map[string]hoconResolved),
+		lookupEnv: p.LookupEnv,
+		limits:    newLimiter(ctx, opts),
+	}
+	resolved, err := r.resolve(root, 0, true)
+	if err != nil {
+		return nil, fmt.Errorf("parse hocon: %w", err)
+	}
+	return resolved.(map[string]interface{}), nil
+}
+
+// Values that only exist until substitutions are resolved.
+type (
+	hoconSubst struct {
+		path     []string
+		optional bool
+	}
+	hoconConcat struct {
+		parts []interface{}
+	}
+	// hoconUnquoted is an unquoted token, converted to a number, boolean or
+	// null when it stands alone.
+	hoconUnquoted string
+	// hoconSpace is whitespace between concatenated values.
+	hoconSpace string
+	// hoconUndefined is a missing optional substitution.
+	hoconUndefined struct{}
+)
+
+// hoconDocument holds what the files of a document share while parsed.
+type hoconDocument struct {
+	includes *Includes
+	limits   *limiter
+}
+
+type hoconParser struct {
+	doc   *hoconDocument
+	src   []rune
+	pos   int
+	file  string
+	depth int
+	root  map[string]interface{}
+	// level is the nesting depth of the object or array being read.
+	level int
+}
+
+// parse reads one file of the document; depth counts includes and level is
+// the nesting depth at which the file is included.
+func (doc *hoconDocument) parse(file string, data []byte, depth, level int) (map[string]interface{}, error) {
+	p := &hoconParser{doc: doc, src: []rune(string(data)), file: file, depth: depth, root: make(map[string]interface{}), level: level}
+
+	p.skipSeparators()
+	var err error
+	if p.peek() == '{' {
+		p.pos++
+		err = p.parseFields(p.root, []string{}, '}')
+		if err == nil {
+			p.skipSeparators()
+			if !p.eof() {
+				err = p.errorf("unexpected %q after the root object", p.peek())
+			}
+		}
+	} else {
+		err = p.parseFields(p.root, []string{}, 0)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return p.root, nil
+}
+
+func (p *hoconParser) errorf(format string, args ...interface{}) error {
+	line := 1 + strings.Count(string(p.src[:p.pos]), "\n")
+	err := fmt.Errorf(format, args...)
+	if p.file != "" {
+		return fmt.Errorf("parse hocon: %s:%d: %w", p.file, line, err)
+	}
+	return fmt.Errorf("parse hocon: line %d: %w", line, err)
+}
+
+func (p *hoconParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *hoconParser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *hoconParser) peekAt(offset int) rune {
+	if p.pos+offset >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos+offset]
+}
+
+func (p *hoconParser) hasPrefix(s string) bool {
+	rs := []rune(s)
+	if p.pos+len(rs) > len(p.src) {
+		return false
+	}
+	return string(p.src[p.pos:p.pos+len(rs)]) == s
+}
+
+func (p *hoconParser) consume(s string) bool {
+	if p.hasPrefix(s) {
+		p.pos += len([]rune(s))
+		return true
+	}
+	return false
+}
+
+func (p *hoconParser) atComment() bool {
+	return p.peek() == '#' || p.hasPrefix("//")
+}
+
+func (p *hoconParser) skipComment() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+// skipSpace skips whitespace other than newlines.
+func (p *hoconParser) skipSpace() {
+	for !p.eof() && p.peek() != '\n' && isHOCONSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+// skipSeparators skips whitespace, newlines, commas and comments.
+func (p *hoconParser) skipSeparators() {
+	for !p.eof() {
+		switch {
+		case isHOCONSpace(p.peek()) || p.peek() == ',':
+			p.pos++
+		case p.atComment():
+			p.skipComment()
+		default:
+			return
+		}
+	}
+}
+
+func isHOCONSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' || r == '\v' || r == '\uFEFF'
+}
+
+// isHOCONForbidden reports characters that cannot appear in unquoted text.
+func isHOCONForbidden(r rune) bool {
+	return strings.ContainsRune("$\"{}[]:=,+#`^?!@*&\\", r)
+}
+
+// parseFields reads fields into target until end (0 for end of input).
+// self is the path of target from the root, or nil when target is not
+// reachable from the root (inside arrays), which disables self-references.
+func (p *hoconParser) parseFields(target map[string]interface{}, self []string, end rune) error {
+	for {
+		p.skipSeparators()
+		if p.eof() {
+			if end == 0 {
+				return nil
+			}
+			return p.errorf("expected %q before end of input", end)
+		}
+		if end != 0 && p.peek() == end {
+			p.pos++
+			return nil
+		}
+
+		if p.atInclude() {
+			if err := p.parseInclude(target); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key, err := p.parseKey()
+		if err != nil {
+			return err
+		}
+		p.skipSpace()
+
+		// full may share the array of self: it is only read while this
+		// field is parsed, and nested fields only write past its end.
+		var full []string
+		if self != nil {
+			full = append(self, key...)
+		}
+		if err := p.doc.limits.nested(p.level + 1); err != nil {
+			return p.errorf("%w", err)
+		}
+
+		var value interface{}
+		switch {
+		case p.peek() == '{':
+			value, err = p.parseValue(full)
+		case p.consume("+="):
+			p.skipSpace()
+			value, err = p.parseValue(full)
+			if err == nil {
+				value, err = p.appendValue(full, value)
+			}
+		case p.peek() == ':' || p.peek() == '=':
+			p.pos++
+			p.skipSpace()
+			value, err = p.parseValue(full)
+		default:
+			return p.errorf("expected ':' or '=' after key %q", strings.Join(key, "."))
+		}
+		if err != nil {
+			return err
+		}
+
+		setHOCONPath(target, key, value)
+
+		p.skipSpace()
+		if !p.eof() && p.peek() != '\n' && p.peek() != ',' && p.peek() != end && !p.atComment() {
+			return p.errorf("unexpected %q after value of %q", p.peek(), strings.Join(key, "."))
+		}
+	}
+}
+
+// parseKey reads a dotted key; quoted segments may contain dots.
+func (p *hoconParser) parseKey() ([]string, error) {
+	var (
+		segments []string
+		current  strings.Builder
+		started  bool
+	)
+
+	for !p.eof() {
+		r := p.peek()
+		switch {
+		case r == '"':
+			s, err := p.parseQuoted()
+			if err != nil {
+				return nil, err
+			}
+			current.WriteString(s)
+			started = true
+		case r == '.':
+			segments = append(segments, current.String())
+			current.Reset()
+			p.pos++
+		case isHOCONSpace(r) || isHOCONForbidden(r) || p.hasPrefix("//"):
+			if !started {
+				return nil, p.errorf("expected a key, got %q", r)
+			}
+			return append(segments, current.String()), nil
+		default:
+			current.WriteRune(r)
+			started = true
+			p.pos++
+		}
+	}
+
+	if !started {
+		return nil, p.errorf("expected a key")
+	}
+	return append(segments, current.String()), nil
+}
+
+// parseValue reads a value and any values concatenated with it on the same line.
+func (p *hoconParser) parseValue(self []string) (interface{}, error) {
+	var parts []interface{}
+
+	for {
+		start := p.pos
+		p.skipSpace()
+		space := string(p.src[start:p.pos])
+
+		if p.eof() || p.atValueEnd() {
+			break
+		}
+		if len(parts) > 0 && space != "" {
+			parts = append(parts, hoconSpace(space))
+		}
+
+		part, err := p.parsePart(self)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+
+	if len(parts) == 0 {
+		return nil, p.errorf("expected a value")
+	}
+	for _, part := range parts {
+		switch part.(type) {
+		case hoconSubst, *hoconConcat:
+			if len(parts) == 1 {
+				return part, nil
+			}
+			return &hoconConcat{parts: parts}, nil
+		}
+	}
+
+	value, err := concatHOCON(parts)
+	if err != nil {
+		return nil, p.errorf("%v", err)
+	}
+	return value, nil
+}
+
+func (p *hoconParser) atValueEnd() bool {
+	r := p.peek()
+	return r == '\n' || r == ',' || r == '}' || r == ']' || p.atComment()
+}
+
+func (p *hoconParser) parsePart(self []string) (interface{}, error) {
+	switch r := p.peek(); {
+	case r == '{':
+		p.pos++
+		p.level++
+		defer func() { p.level-- }()
+
+		obj := make(map[string]interface{})
+		if err := p.parseFields(obj, self, '}'); err != nil {
+			return nil, err
+		}
+		return obj, nil
+
+	case r == '[':
+		return p.parseArray()
+
+	case r == '"':
+		return p.parseQuoted()
+
+	case r == '$' && p.peekAt(1) == '{':
+		return p.parseSubst(self)
+
+	default:
+		start := p.pos
+		for !p.eof() && !isHOCONSpace(p.peek()) && !isHOCONForbidden(p.peek()) && !p.hasPrefix("//") {
+			p.pos++
+		}
+		if p.pos == start {
+			return nil, p.errorf("unexpected %q", r)
+		}
+		return hoconUnquoted(p.src[start:p.pos]), nil
+	}
+}
+
+func (p *hoconParser) parseArray() (interface{}, error) {
+	p.pos++
+	p.level++
+	defer func() { p.level-- }()
+	items := []interface{}{}
+
+	for {
+		p.skipSeparators()
+		if p.eof() {
+			return nil, p.errorf("expected ']' before end of input")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return items, nil
+		}
+
+		if err := p.doc.limits.nested(p.level + 1); err != nil {
+			return nil, p.errorf("%w", err)
+		}
+		item, err := p.parseValue(nil)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+}
+
+// parseQuoted reads a JSON string or a """triple-quoted""" raw string.
+func (p *hoconParser) parseQuoted() (string, error) {
+	if p.consume(`"""`) {
+		start := p.pos
+		for !p.eof() && !p.hasPrefix(`"""`) {
+			p.pos++
+		}
+		if p.eof() {
+			return "", p.errorf("unterminated triple-quoted string")
+		}
+		// Quotes right before the closing ones belong to the string.
+		for p.peekAt(3) == '"' {
+			p.pos++
+		}
+		s := string(p.src[start:p.pos])
+		p.pos += 3
+		return s, nil
+	}
+
+	start := p.pos
+	p.pos++
+	for !p.eof() && p.peek() != '"' && p.peek() != '\n' {
+		if p.peek() == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	if p.peek() != '"' {
+		return "", p.errorf("unterminated string")
+	}
+	p.pos++
+
+	var s string
+	if err := json.Unmarshal([]byte(string(p.src[start:p.pos])), &s); err != nil {
+		return "", p.errorf("invalid string: %v", err)
+	}
+	return s, nil
+}
+
+// parseSubst reads ${path} or ${?path}. A reference to the field being
+// defined is resolved right away against its previous value.
+func (p *hoconParser) parseSubst(self []string) (interface{}, error) {
+	p.pos += 2
+	optional := p.consume("?")
+
+	p.skipSpace()
+	path, err := p.parseKey()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.consume("}") {
+		return nil, p.errorf("expected '}' after substitution")
+	}
+
+	if self != nil && equalPath(path, self) {
+		if prev, ok := lookupHOCON(p.root, path); ok {
+			return copyHOCON(prev), nil
+		}
+		if optional {
+			return hoconUndefined{}, nil
+		}
+		return nil, p.errorf("self-referential substitution ${%s} has no previous value", strings.Join(path, "."))
+	}
+
+	return hoconSubst{path: path, optional: optional}, nil
+}
+
+// appendValue implements "key += value".
+func (p *hoconParser) appendValue(full []string, value interface{}) (interface{}, error) {
+	var prev interface{}
+	if full != nil {
+		prev, _ = lookupHOCON(p.root, full)
+	}
+
+	switch existing := prev.(type) {
+	case nil:
+		return []interface{}{value}, nil
+	case []interface{}:
+		return append(copyHOCON(existing).([]interface{}), value), nil
+	case hoconSubst, *hoconConcat:
+		return &hoconConcat{parts: []interface{}{existing, []interface{}{value}}}, nil
+	default:
+		return nil, p.errorf("cannot append to a non-array value")
+	}
+}
+
+func (p *hoconParser) atInclude() bool {
+	if !p.hasPrefix("include") {
+		return false
+	}
+	rest := p.pos + len("include")
+	if rest >= len(p.src) || !isHOCONSpace(p.src[rest]) {
+		return false
+	}
+	for rest < len(p.src) && isHOCONSpace(p.src[rest]) && p.src[rest] != '\n' {
+		rest++
+	}
+	next := string(p.src[rest:min(rest+len("classpath("), len(p.src))])
+	for _, prefix := range []string{`"`, "file(", "required(", "url(", "classpath("} {
+		if strings.HasPrefix(next, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseInclude reads an include directive and merges the included file into target.
+func (p *hoconParser) parseInclude(target map[string]interface{}) error {
+	p.pos += len("include")
+	p.skipSpace()
+
+	required := p.consume("required(")
+	closing := 0
+	if required {
+		closing++
+	}
+
+	switch {
+	case p.consume("file("):
+		closing++
+	case p.hasPrefix("url(") || p.hasPrefix("classpath("):
+		return p.errorf("only file includes are supported")
+	}
+
+	name, err := p.parseQuoted()
+	if err != nil {
+		return err
+	}
+	for ; closing > 0; closing-- {
+		if !p.consume(")") {
+			return p.errorf("expected ')' in include")
+		}
+	}
+
+	if p.depth >= maxIncludeDepth {
+		return p.errorf("includes nested deeper than %d", maxIncludeDepth)
+	}
+
+	path, data, err := p.doc.includes.Load(p.file, name)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return p.errorf("include %q: %w", name, err)
+	}
+
+	included, err := p.doc.parse(path, data, p.depth+1, p.level)
+	if err != nil {
+		return err
+	}
+	mergeHOCON(target, included)
+	return nil
+}
+
+// setHOCONPath stores value under key, merging objects into existing ones.
+func setHOCONPath(target map[string]interface{}, key []string, value interface{}) {
+	m := target
+	for _, segment := range key[:len(key)-1] {
+		child, ok := m[segment].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			m[segment] = child
+		}
+		m = child
+	}
+
+	last := key[len(key)-1]
+	if obj, ok := value.(map[string]interface{}); ok {
+		if existing, ok := m[last].(map[string]interface{}); ok {
+			mergeHOCON(existing, obj)
+			return
+		}
+	}
+	m[last] = value
+}
+
+// mergeHOCON merges src into dst; objects merge recursively, other values
+// from src replace those in dst.
+func mergeHOCON(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if obj, ok := v.(map[string]interface{}); ok {
+			if existing, ok := dst[k].(map[string]interface{}); ok {
+				mergeHOCON(existing, obj)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+func lookupHOCON(root map[string]interface{}, path []string) (interface{}, bool) {
+	var cur interface{} = root
+	for _, segment := range path {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[segment]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func copyHOCON(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[k] = copyHOCON(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = copyHOCON(item)
+		}
+		return out
+	case *hoconConcat:
+		return &hoconConcat{parts: copyHOCON(val.parts).([]interface{})}
+	default:
+		return v
+	}
+}
+
+func equalPath(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// concatHOCON joins resolved parts: objects merge, arrays append and
+// anything else becomes a string. A lone unquoted token keeps its type.
+func concatHOCON(parts []interface{}) (interface{}, error) {
+	var values []interface{}
+	for _, part := range parts {
+		if _, ok := part.(hoconUndefined); !ok {
+			values = append(values, part)
+		}
+	}
+
+	var nonSpace []interface{}
+	for _, v := range values {
+		if _, ok := v.(hoconSpace); !ok {
+			nonSpace = append(nonSpace, v)
+		}
+	}
+
+	switch {
+	case len(nonSpace) == 0:
+		return hoconUndefined{}, nil
+	case len(nonSpace) == 1:
+		return scalarHOCON(nonSpace[0]), nil
+	}
+
+	if _, ok := nonSpace[0].(map[string]interface{}); ok {
+		merged := make(map[string]interface{})
+		for _, v := range nonSpace {
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("cannot concatenate an object with %s", hoconKind(v))
+			}
+			mergeHOCON(merged, copyHOCON(obj).(map[string]interface{}))
+		}
+		return merged, nil
+	}
+
+	if _, ok := nonSpace[0].([]interface{}); ok {
+		joined := []interface{}{}
+		for _, v := range nonSpace {
+			arr, ok := v.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("cannot concatenate an array with %s", hoconKind(v))
+			}
+			joined = append(joined, arr...)
+		}
+		return joined, nil
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		switch val := v.(type) {
+		case hoconSpace:
+			sb.WriteString(string(val))
+		case hoconUnquoted:
+			sb.WriteString(string(val))
+		case string:
+			sb.WriteString(val)
+		case float64:
+			sb.WriteString(strconv.FormatFloat(val, 'f', -1, 64))
+		case bool:
+			sb.WriteString(strconv.FormatBool(val))
+		case nil:
+			sb.WriteString("null")
+		default:
+			return nil, fmt.Errorf("cannot concatenate a string with %s", hoconKind(v))
+		}
+	}
+	return sb.String(), nil
+}
+
+func hoconKind(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	default:
+		return "a string"
+	}
+}
+
+// scalarHOCON converts a lone unquoted token to a number, boolean or null.
+func scalarHOCON(v interface{}) interface{} {
+	token, ok := v.(hoconUnquoted)
+	if !ok {
+		return v
+	}
+
+	switch s := string(token); s {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	default:
+		// Only JSON number syntax counts, not "inf", "0x10" or "1_000".
+		if n, err := strconv.ParseFloat(s, 64); err == nil && json.Valid([]byte(s)) {
+			return n
+		}
+		return s
+	}
+}
+
+type hoconResolver struct {
+	ctx       context.Context
+	root      map[string]interface{}
+	resolving map[string]bool
+	// resolved memoises substitution targets by path.
+	resolved  map[string]hoconResolved
+	lookupEnv func(name string) (string, bool)
+	limits    *limiter
+	// copied and text count the nodes and bytes substitutions expand to.
+	copied int
+	text   int
+}
+
+type hoconResolved struct {
+	value interface{}
+	found bool
+}
+
+// resolve resolves v at depth. count is false while resolving the target of
+// a substitution, whose values are counted where the target itself is
+// resolved and where copies of it are used.
+func (r *hoconResolver) resolve(v interface{}, depth int, count bool) (interface{}, error) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			if count {
+				if err := r.limits.nested(depth + 1); err != nil {
+					return nil, err
+				}
+			}
+			resolved, err := r.resolve(item, depth+1, count)
+			if err != nil {
+				return nil, err
+			}
+			if _, undefined := resolved.(hoconUndefined); !undefined {
+				out[k] = resolved
+			}
+		}
+		return out, nil
+
+	case []interface{}:
+		out := make([]interface{}, 0, len(val))
+		for _, item := range val {
+			if count {
+				if err := r.limits.nested(depth + 1); err != nil {
+					return nil, err
+				}
+			}
+			resolved, err := r.resolve(item, depth+1, count)
+			if err != nil {
+				return nil, err
+			}
+			if _, undefined := resolved.(hoconUndefined); !undefined {
+				out = append(out, resolved)
+			}
+		}
+		return out, nil
+
+	case hoconSubst:
+		return r.substitute(val, depth)
+
+	case *hoconConcat:
+		parts := make([]interface{}, len(val.parts))
+		for i, part := range val.parts {
+			resolved, err := r.resolve(part, depth, count)
+			if err != nil {
+				return nil, err
+			}
+			parts[i] = resolved
+		}
+		value, err := concatHOCON(parts)
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := value.(string); ok {
+			r.text += len(s)
+			if r.text > maxHOCONSubstText {
+				return nil, fmt.Errorf("substitutions expand to more than %d bytes of text", maxHOCONSubstText)
+			}
+		}
+		return value, nil
+
+	case hoconUnquoted:
+		return scalarHOCON(val), nil
+
+	default:
+		return v, nil
+	}
+}
+
+// substitute returns a copy of the value s refers to, placed at depth.
+func (r *hoconResolver) substitute(s hoconSubst, depth int) (interface{}, error) {
+	if err := r.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	target, err := r.lookup(s.path)
+	if err != nil {
+		return nil, err
+	}
+	if target.found {
+		return r.copy(target.value, depth)
+	}
+
+	name := strings.Join(s.path, ".")
+	if r.lookupEnv != nil {
+		if env, ok := r.lookupEnv(name); ok {
+			return env, nil
+		}
+	}
+	if s.optional {
+		return hoconUndefined{}, nil
+	}
+	return nil, fmt.Errorf("unresolved substitution ${%s}", name)
+}
+
+// lookup resolves the value at path once; later lookups reuse it.
+func (r *hoconResolver) lookup(path []string) (hoconResolved, error) {
+	key := strings.Join(path, "\x00")
+	if target, ok := r.resolved[key]; ok {
+		return target, nil
+	}
+	if r.resolving[key] {
+		return hoconResolved{}, fmt.Errorf("substitution cycle at ${%s}", strings.Join(path, "."))
+	}
+	r.resolving[key] = true
+	defer delete(r.resolving, key)
+
+	var cur interface{} = r.root
+	for i, segment := range path {
+		switch cur.(type) {
+		case hoconSubst, *hoconConcat:
+			parent, err := r.lookup(path[:i])
+			if err != nil {
+				return hoconResolved{}, err
+			}
+			cur = parent.value
+		}
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return r.remember(key, hoconResolved{})
+		}
+		if cur, ok = m[segment]; !ok {
+			return r.remember(key, hoconResolved{})
+		}
+	}
+
+	value, err := r.resolve(cur, len(path), false)
+	if err != nil {
+		return hoconResolved{}, err
+	}
+	return r.remember(key, hoconResolved{value: value, found: true})
+}
+
+func (r *hoconResolver) remember(key string, target hoconResolved) (hoconResolved, error) {
+	r.resolved[key] = target
+	return target, nil
+}
+
+// copy copies a resolved value placed at depth, counting its nodes.
+func (r *hoconResolver) copy(v interface{}, depth int) (interface{}, error) {
+	r.copied++
+	if r.copied > maxHOCONSubstNodes {
+		return nil, errors.New("substitutions expand to too many values")
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			if err := r.limits.nested(depth + 1); err != nil {
+				return nil, err
+			}
+			c, err := r.copy(item, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = c
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			if err := r.limits.nested(depth + 1); err != nil {
+				return nil, err
+			}
+			c, err := r.copy(item, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = c
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
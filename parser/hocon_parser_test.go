@@ -0,0 +1,316 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHOCONParser_Parse(t *testing.T) {
+	env := map[string]string{"GENDIFF_HOCON_HOME": "/home/app"}
+	p := &HOCONParser{LookupEnv: func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}}
+
+	tests := []struct {
+		name      string
+		data      string
+		expected  map[string]interface{}
+		expectErr bool
+	}{
+		{
+			name: "syntax",
+			data: `
+# comment
+// another comment
+akka {
+  loglevel = "INFO"
+  actor.provider: cluster
+  remote { port = 2552, enabled = true }
+}
+timeout = 30 seconds
+ratio = 0.5
+empty = null
+list = [1, two, "three"
+  4]
+"quoted.key" = x
+raw = """a "b"
+c"""
+`,
+			expected: map[string]interface{}{
+				"akka": map[string]interface{}{
+					"loglevel": "INFO",
+					"actor":    map[string]interface{}{"provider": "cluster"},
+					"remote":   map[string]interface{}{"port": 2552.0, "enabled": true},
+				},
+				"timeout":    "30 seconds",
+				"ratio":      0.5,
+				"empty":      nil,
+				"list":       []interface{}{1.0, "two", "three", 4.0},
+				"quoted.key": "x",
+				"raw":        "a \"b\"\nc",
+			},
+		},
+		{
+			name: "root braces and merging",
+			data: `{
+  a { x = 1, y = 2 }
+  a { y = 3 }
+  a.z = 4
+  b = 1
+  b = { c = 2 }
+}`,
+			expected: map[string]interface{}{
+				"a": map[string]interface{}{"x": 1.0, "y": 3.0, "z": 4.0},
+				"b": map[string]interface{}{"c": 2.0},
+			},
+		},
+		{
+			name: "substitutions",
+			data: `
+base { host = localhost, port = 80 }
+service = ${base} { port = 8080 }
+url = "http://"${base.host}":"${service.port}/api
+later = ${forward}
+forward = value
+home = ${GENDIFF_HOCON_HOME}
+missing = ${?GENDIFF_HOCON_UNSET}
+path = /bin
+path = ${path}":/usr/bin"
+items = [a]
+items += b
+items = ${items} [c]
+`,
+			expected: map[string]interface{}{
+				"base":    map[string]interface{}{"host": "localhost", "port": 80.0},
+				"service": map[string]interface{}{"host": "localhost", "port": 8080.0},
+				"url":     "http://localhost:8080/api",
+				"later":   "value",
+				"forward": "value",
+				"home":    "/home/app",
+				"path":    "/bin:/usr/bin",
+				"items":   []interface{}{"a", "b", "c"},
+			},
+		},
+		{name: "unresolved", data: "a = ${nope}", expectErr: true},
+		{name: "cycle", data: "a = ${b}\nb = ${a}", expectErr: true},
+		{name: "missing separator", data: "a 1", expectErr: true},
+		{name: "unterminated object", data: "a { b = 1", expectErr: true},
+		{name: "bad concatenation", data: "a = [1] x", expectErr: true},
+		{name: "include without file", data: `include "other.conf"`, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := p.Parse([]byte(tt.data))
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestHOCONParser_EnvFallbackIsOptIn(t *testing.T) {
+	t.Setenv("GENDIFF_HOCON_HOME", "/home/app")
+
+	_, err := (&HOCONParser{}).Parse([]byte("home = ${GENDIFF_HOCON_HOME}"))
+	require.ErrorContains(t, err, "unresolved substitution")
+
+	result, err := (&HOCONParser{}).Parse([]byte("home = ${?GENDIFF_HOCON_HOME}"))
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{}, result)
+
+	result, err = (&HOCONParser{LookupEnv: os.LookupEnv}).Parse([]byte("home = ${GENDIFF_HOCON_HOME}"))
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"home": "/home/app"}, result)
+}
+
+func TestHOCONParser_Include(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	write("defaults.conf", "db { host = localhost, port = 5432 }\nname = default")
+	write("loop.conf", `include "loop.conf"`)
+	main := write("app.conf", `include "defaults.conf"
+include file("missing.conf")
+db.host = db.prod
+url = ${db.host}":"${db.port}`)
+
+	result, err := (&HOCONParser{}).ParsePath(main, []byte(mustRead(t, main)))
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"db":   map[string]interface{}{"host": "db.prod", "port": 5432.0},
+		"name": "default",
+		"url":  "db.prod:5432",
+	}, result)
+
+	required := write("required.conf", `include required(file("missing.conf"))`)
+	_, err = (&HOCONParser{}).ParsePath(required, []byte(mustRead(t, required)))
+	require.Error(t, err)
+
+	loop := filepath.Join(dir, "loop.conf")
+	_, err = (&HOCONParser{}).ParsePath(loop, []byte(mustRead(t, loop)))
+	require.Error(t, err)
+
+	fp := NewFileParser()
+	fp.Add(&HOCONParser{}, ".conf")
+	result, err = fp.Parse(main)
+	require.NoError(t, err)
+	require.Equal(t, "db.prod:5432", result["url"])
+
+	// Each file fits the limit, but not the two includes together.
+	write("a.conf", "a = "+strings.Repeat("x", 26))
+	write("b.conf", "b = "+strings.Repeat("x", 26))
+	both := write("both.conf", "include \"a.conf\"\ninclude \"b.conf\"")
+	_, err = fp.ParseFile(context.Background(), both, ParseOptions{MaxSize: 60})
+	require.NoError(t, err)
+	_, err = fp.ParseFile(context.Background(), both, ParseOptions{MaxSize: 50})
+	require.ErrorIs(t, err, ErrInputTooLarge)
+}
+
+func TestHOCONParser_IncludeOutsideDir(t *testing.T) {
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "secret.conf")
+	require.NoError(t, os.WriteFile(secret, []byte("password = hunter2"), 0o600))
+
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "shared.conf"), []byte("a = 1"), 0o600))
+	require.NoError(t, os.Symlink(secret, filepath.Join(dir, "link.conf")))
+
+	rel, err := filepath.Rel(dir, secret)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		include   string
+		expectErr error
+	}{
+		{name: "subdirectory", include: "sub/shared.conf"},
+		{name: "parent directory", include: rel, expectErr: ErrIncludeOutsideDir},
+		{name: "absolute path", include: secret, expectErr: ErrIncludeOutsideDir},
+		{name: "symlink", include: "link.conf", expectErr: ErrIncludeOutsideDir},
+		{name: "optional outside", include: "file(\"" + secret + "\")", expectErr: ErrIncludeOutsideDir},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			include := tt.include
+			if !strings.HasPrefix(include, "file(") {
+				include = strconv.Quote(include)
+			}
+			main := filepath.Join(dir, "app.conf")
+			data := []byte("include " + include)
+
+			result, err := (&HOCONParser{}).ParsePath(main, data)
+			if tt.expectErr != nil {
+				require.ErrorIs(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, map[string]interface{}{"a": 1.0}, result)
+		})
+	}
+}
+
+func mustRead(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestHOCONParser_Limits(t *testing.T) {
+	doubling := func(first, line string) string {
+		var sb strings.Builder
+		sb.WriteString(first + "\n")
+		for i := 1; i <= 26; i++ {
+			fmt.Fprintf(&sb, line+"\n", i, i-1, i-1)
+		}
+		return sb.String()
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		data   string
+		opts   ParseOptions
+		target error
+	}{
+		{
+			name: "doubling strings",
+			data: doubling(`a0 = "xxxxxxxxxxxxxxxx"`, "a%d = ${a%d} ${a%d}"),
+			opts: ParseOptions{MaxNodes: 1000},
+		},
+		{
+			name:   "doubling objects",
+			data:   doubling(`a0 = {x = 1}`, "a%d = {l = ${a%d}, r = ${a%d}}"),
+			opts:   ParseOptions{MaxNodes: 1000},
+			target: ErrMaxNodesExceeded,
+		},
+		{
+			name: "doubling objects without limits",
+			data: doubling(`a0 = {x = 1}`, "a%d = {l = ${a%d}, r = ${a%d}}"),
+		},
+		{
+			name:   "nested arrays",
+			data:   "a = " + strings.Repeat("[", 6<<20),
+			opts:   ParseOptions{MaxDepth: 50},
+			target: ErrMaxDepthExceeded,
+		},
+		{
+			name:   "nested objects without limits",
+			data:   "a = " + strings.Repeat("{b = ", 1<<20),
+			target: ErrMaxDepthExceeded,
+		},
+		{
+			name:   "depth of substituted values",
+			data:   "a = {b = {c = 1}}\nd = {e = ${a}}",
+			opts:   ParseOptions{MaxDepth: 3},
+			target: ErrMaxDepthExceeded,
+		},
+		{
+			name:   "cancelled",
+			ctx:    cancelled,
+			data:   "a = 1\nb = ${a}",
+			target: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			_, err := (&HOCONParser{}).ParseContext(ctx, "", []byte(tt.data), tt.opts)
+			require.Error(t, err)
+			if tt.target != nil {
+				require.ErrorIs(t, err, tt.target)
+			}
+		})
+	}
+
+	result, err := (&HOCONParser{}).ParseContext(context.Background(), "", []byte("a = {b = {c = 1}}\nd = ${a}"), ParseOptions{MaxDepth: 3, MaxNodes: 6})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"c": 1.0}, result["d"].(map[string]interface{})["b"])
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrIncludeWithoutPath = errors.New("include needs the path of the including file")
+	ErrIncludeOutsideDir  = errors.New("include is outside the directory of the input")
+)
+
+// Includes reads the files a document pulls in, such as HOCON and YAML
+// includes or $ref targets. Paths are relative to the including file and
+// must stay inside the directory of the input, after following symlinks.
+// Together the included files may not exceed ParseOptions.MaxSize, and
+// reading stops once ctx is done.
+type Includes struct {
+	ctx     context.Context
+	dir     string
+	maxSize int64
+	budget  *readBudget
+}
+
+// NewIncludes returns the Includes of the input read from file, which is
+// empty for readers; their includes fail with ErrIncludeWithoutPath. Files
+// parsed with Includes.ParseFile share the Includes of their input.
+func NewIncludes(ctx context.Context, file string, opts ParseOptions) *Includes {
+	if opts.includes != nil {
+		return opts.includes
+	}
+
+	in := &Includes{ctx: ctx, maxSize: opts.MaxSize, budget: &readBudget{remaining: opts.MaxSize}}
+	if file == "" {
+		return in
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err == nil {
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			dir = real
+		}
+	}
+	in.dir = dir
+	return in
+}
+
+// Path resolves name, as written in the file from, and checks that it is
+// inside the directory of the input. The file does not have to exist.
+func (in *Includes) Path(from, name string) (string, error) {
+	if in.dir == "" || from == "" {
+		return "", fmt.Errorf("%w: %s", ErrIncludeWithoutPath, name)
+	}
+
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(from), path)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrAbsPath, name)
+	}
+
+	real := path
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		real = resolved
+	}
+	rel, err := filepath.Rel(in.dir, real)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrIncludeOutsideDir, name)
+	}
+	return path, nil
+}
+
+// Read reads a file returned by Path, charging it to the size budget.
+func (in *Includes) Read(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrReadFile, err)
+	}
+	defer f.Close()
+
+	data, err := in.budget.read(in.ctx, f, in.maxSize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, path)
+	}
+	return data, nil
+}
+
+// ParseFile parses the file name, as written in the file from, with fp. Its
+// own includes are read through in.
+func (in *Includes) ParseFile(fp *FileParser, from, name string, opts ParseOptions) (map[string]interface{}, error) {
+	path, data, err := in.Load(from, name)
+	if err != nil {
+		return nil, err
+	}
+	opts.includes = in
+	return fp.decode(in.ctx, path, path, data, opts)
+}
+
+// Load is Path followed by Read.
+func (in *Includes) Load(from, name string) (string, []byte, error) {
+	path, err := in.Path(from, name)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := in.Read(path)
+	return path, data, err
+}
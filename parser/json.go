@@ -0,0 +1,334 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"code/diff"
+	"code/internal/utils"
+)
+
+// JSONParser reads JSON. With Lenient set it also accepts what JSONC files
+// use: comments, trailing commas, unquoted keys and single-quoted strings.
+// That is a subset of JSON5: hexadecimal numbers, leading or trailing
+// decimal points, leading plus signs, Infinity and NaN are rejected.
+type JSONParser struct {
+	Lenient bool
+}
+
+func (p *JSONParser) Parse(data []byte) (map[string]interface{}, error) {
+	return p.ParseContext(context.Background(), "", data, ParseOptions{})
+}
+
+// ParseContext checks the limits of opts in a pass over the tokens of data
+// before decoding it.
+func (p *JSONParser) ParseContext(ctx context.Context, _ string, data []byte, opts ParseOptions) (map[string]interface{}, error) {
+	if p.Lenient {
+		normalized, err := normalizeJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse json: %w", err)
+		}
+		data = normalized
+	}
+
+	if l := newLimiter(ctx, opts); l != nil {
+		if err := limitJSON(l, data); err != nil {
+			return nil, fmt.Errorf("parse json: %w", err)
+		}
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse json: %w", err)
+	}
+	return result, nil
+}
+
+// limitJSON feeds the values of data to l without building them. Syntax
+// errors are left to json.Unmarshal.
+func limitJSON(l *limiter, data []byte) error {
+	type frame struct {
+		object    bool
+		expectKey bool
+	}
+
+	var stack []frame
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+
+		delim, isDelim := tok.(json.Delim)
+		if isDelim && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		if n := len(stack); n > 0 {
+			top := &stack[n-1]
+			if top.object && top.expectKey {
+				top.expectKey = false
+				continue
+			}
+			top.expectKey = top.object
+			if err := l.value(n); err != nil {
+				return err
+			}
+		}
+		if isDelim {
+			stack = append(stack, frame{object: delim == '{', expectKey: delim == '{'})
+		}
+	}
+}
+
+// Check reports keys that appear more than once in the same object, of
+// which encoding/json silently keeps the last.
+func (p *JSONParser) Check(data []byte) []diff.Warning {
+	if p.Lenient {
+		normalized, err := normalizeJSON(data)
+		if err != nil {
+			return nil
+		}
+		data = normalized
+	}
+
+	type frame struct {
+		object    bool
+		expectKey bool
+		path      string
+		key       string
+		index     int
+		keys      map[string]int
+	}
+
+	var (
+		warnings []diff.Warning
+		stack    []*frame
+	)
+	childPath := func() string {
+		if len(stack) == 0 {
+			return ""
+		}
+		top := stack[len(stack)-1]
+		if top.object {
+			return utils.JoinPath(top.path, top.key)
+		}
+		return fmt.Sprintf("%s[%d]", top.path, top.index)
+	}
+	valueDone := func() {
+		if len(stack) == 0 {
+			return
+		}
+		top := stack[len(stack)-1]
+		if top.object {
+			top.expectKey = true
+		} else {
+			top.index++
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return warnings
+		}
+
+		switch t := tok.(type) {
+		case json.Delim:
+			if t == '{' || t == '[' {
+				object := t == '{'
+				stack = append(stack, &frame{object: object, expectKey: object, path: childPath(), keys: make(map[string]int)})
+				continue
+			}
+			stack = stack[:len(stack)-1]
+			valueDone()
+
+		case string:
+			if len(stack) == 0 || !stack[len(stack)-1].expectKey {
+				valueDone()
+				continue
+			}
+			top := stack[len(stack)-1]
+			line := 1 + bytes.Count(data[:dec.InputOffset()], []byte("\n"))
+			if first, exists := top.keys[t]; exists {
+				warnings = append(warnings, diff.Warning{
+					Line:    line,
+					Path:    utils.JoinPath(top.path, t),
+					Message: fmt.Sprintf("duplicate key, first defined on line %d; the last value is used", first),
+				})
+			} else {
+				top.keys[t] = line
+			}
+			top.key = t
+			top.expectKey = false
+
+		default:
+			valueDone()
+		}
+	}
+}
+
+// normalizeJSON rewrites lenient JSON into strict JSON. Comments become
+// spaces, keeping their newlines, so that syntax errors and warnings point
+// close to the source.
+func normalizeJSON(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	out.Grow(len(data))
+
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '"':
+			end, err := skipJSONString(data, i)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(data[i:end])
+			i = end
+
+		case c == '\'':
+			end, err := skipJSONString(data, i)
+			if err != nil {
+				return nil, err
+			}
+			writeSingleQuoted(&out, data[i+1:end-1])
+			i = end
+
+		case c == '/' && i+1 < len(data) && (data[i+1] == '/' || data[i+1] == '*'):
+			end, err := skipJSONComment(data, i)
+			if err != nil {
+				return nil, err
+			}
+			for _, b := range data[i:end] {
+				if b != '\n' {
+					b = ' '
+				}
+				out.WriteByte(b)
+			}
+			i = end
+
+		case c == ',':
+			next, err := skipJSONSpace(data, i+1)
+			if err != nil {
+				return nil, err
+			}
+			if next >= len(data) || (data[next] != '}' && data[next] != ']') {
+				out.WriteByte(c)
+			}
+			i++
+
+		case isJSONIdentStart(c):
+			end := i + 1
+			for end < len(data) && (isJSONIdentStart(data[end]) || (data[end] >= '0' && data[end] <= '9')) {
+				end++
+			}
+			next, err := skipJSONSpace(data, end)
+			if err != nil {
+				return nil, err
+			}
+			if next < len(data) && data[next] == ':' {
+				out.WriteByte('"')
+				out.Write(data[i:end])
+				out.WriteByte('"')
+			} else {
+				out.Write(data[i:end])
+			}
+			i = end
+
+		case c >= '0' && c <= '9':
+			// Keep exponents such as 1e5 from being read as identifiers.
+			for i < len(data) && (isJSONIdentStart(data[i]) || (data[i] >= '0' && data[i] <= '9') || data[i] == '.') {
+				out.WriteByte(data[i])
+				i++
+			}
+
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return out.Bytes(), nil
+}
+
+func isJSONIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// skipJSONString returns the offset just past the string quoted by data[start].
+func skipJSONString(data []byte, start int) (int, error) {
+	quote := data[start]
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1, nil
+		}
+	}
+	return 0, errors.New("unterminated string")
+}
+
+// skipJSONComment returns the offset just past the comment at start.
+func skipJSONComment(data []byte, start int) (int, error) {
+	if data[start+1] == '/' {
+		end := bytes.IndexByte(data[start:], '\n')
+		if end < 0 {
+			return len(data), nil
+		}
+		return start + end, nil
+	}
+
+	end := bytes.Index(data[start+2:], []byte("*/"))
+	if end < 0 {
+		return 0, errors.New("unterminated comment")
+	}
+	return start + 2 + end + 2, nil
+}
+
+// skipJSONSpace returns the offset of the next byte that is neither
+// whitespace nor part of a comment.
+func skipJSONSpace(data []byte, i int) (int, error) {
+	for i < len(data) {
+		switch c := data[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '/' && i+1 < len(data) && (data[i+1] == '/' || data[i+1] == '*'):
+			end, err := skipJSONComment(data, i)
+			if err != nil {
+				return 0, err
+			}
+			i = end
+		default:
+			return i, nil
+		}
+	}
+	return i, nil
+}
+
+// writeSingleQuoted writes the body of a single-quoted string as a
+// double-quoted one.
+func writeSingleQuoted(out *bytes.Buffer, body []byte) {
+	out.WriteByte('"')
+	for i := 0; i < len(body); i++ {
+		switch c := body[i]; {
+		case c == '\\' && i+1 < len(body) && body[i+1] == '\'':
+			out.WriteByte('\'')
+			i++
+		case c == '\\' && i+1 < len(body):
+			out.Write(body[i : i+2])
+			i++
+		case c == '"':
+			out.WriteString(`\"`)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	out.WriteByte('"')
+}
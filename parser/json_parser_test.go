@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"testing"
+
+	"code/diff"
+
+	"github.com/stretchr/testify/require"
+)
+
+type jsonParserCase struct {
+	name      string
+	data      []byte
+	expected  map[string]interface{}
+	expectErr bool
+}
+
+func TestJSONParser_Parse(t *testing.T) {
+	parser := &JSONParser{}
+
+	tests := []jsonParserCase{
+		{
+			name: "valid nested json",
+			data: []byte(`{"foo": 1, "bar": {"baz": true}}`),
+			expected: map[string]interface{}{
+				"foo": float64(1),
+				"bar": map[string]interface{}{"baz": true},
+			},
+		},
+		{
+			name:      "invalid json",
+			data:      []byte("{invalid"),
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parser.Parse(tt.data)
+			if tt.expectErr {
+				require.Error(t, err)
+				require.Nil(t, result)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestJSONParser_Lenient(t *testing.T) {
+	tests := []jsonParserCase{
+		{
+			name: "comments and trailing commas",
+			data: []byte(`{
+  // editor settings
+  "editor.tabSize": 2, /* inline */
+  "files.exclude": {"**/.git": true,},
+  "list": [1e3, 2,],
+}`),
+			expected: map[string]interface{}{
+				"editor.tabSize": 2.0,
+				"files.exclude":  map[string]interface{}{"**/.git": true},
+				"list":           []interface{}{1000.0, 2.0},
+			},
+		},
+		{
+			name: "unquoted keys and single quotes",
+			data: []byte(`{name: 'it\'s "here"', $id: 'a\nb', nested: {ok: true, none: null}}`),
+			expected: map[string]interface{}{
+				"name":   `it's "here"`,
+				"$id":    "a\nb",
+				"nested": map[string]interface{}{"ok": true, "none": nil},
+			},
+		},
+		{
+			name: "comment markers inside strings",
+			data: []byte(`{"url": "http://example.com/*x*/", 'path': '//srv'}`),
+			expected: map[string]interface{}{
+				"url":  "http://example.com/*x*/",
+				"path": "//srv",
+			},
+		},
+		{
+			name:      "unterminated comment",
+			data:      []byte(`{"a": 1 /* }`),
+			expectErr: true,
+		},
+		{
+			name:      "unterminated string",
+			data:      []byte(`{'a: 1}`),
+			expectErr: true,
+		},
+		{
+			name:      "bare value",
+			data:      []byte(`{"a": yes}`),
+			expectErr: true,
+		}, {name: "json5 hexadecimal number", data: []byte(`{a: 0x1F}`), expectErr: true},
+		{name: "json5 leading decimal point", data: []byte(`{a: .5}`), expectErr: true},
+		{name: "json5 trailing decimal point", data: []byte(`{a: 5.}`), expectErr: true},
+		{name: "json5 plus sign", data: []byte(`{a: +1}`), expectErr: true},
+		{name: "json5 infinity", data: []byte(`{a: Infinity}`), expectErr: true},
+		{name: "json5 nan", data: []byte(`{a: NaN}`), expectErr: true},
+	}
+
+	parser := &JSONParser{Lenient: true}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parser.Parse(tt.data)
+			if tt.expectErr {
+				require.Error(t, err)
+				require.Nil(t, result)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestJSONParser_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		parser   *JSONParser
+		data     string
+		expected []diff.Warning
+	}{
+		{
+			name:   "no duplicates",
+			parser: &JSONParser{},
+			data:   `{"a": {"b": 1}, "c": [{"b": 2}, {"b": 3}]}`,
+		},
+		{
+			name:   "nested duplicates",
+			parser: &JSONParser{},
+			data:   "{\n  \"a\": 1,\n  \"list\": [\n    {\"x\": 1,\n     \"x\": 2}\n  ],\n  \"a\": 2,\n  \"\": 1,\n  \"\": 2\n}",
+			expected: []diff.Warning{
+				{Line: 5, Path: "list[0].x", Message: "duplicate key, first defined on line 4; the last value is used"},
+				{Line: 7, Path: "a", Message: "duplicate key, first defined on line 2; the last value is used"},
+				{Line: 9, Path: "", Message: "duplicate key, first defined on line 8; the last value is used"},
+			},
+		},
+		{
+			name:   "lenient keeps lines",
+			parser: &JSONParser{Lenient: true},
+			data:   "{\n  /* a\n     b */\n  a: 1,\n  'a': 2,\n}",
+			expected: []diff.Warning{
+				{Line: 5, Path: "a", Message: "duplicate key, first defined on line 4; the last value is used"},
+			},
+		},
+		{
+			name:   "invalid json",
+			parser: &JSONParser{},
+			data:   `{"a": 1, "a"`,
+			expected: []diff.Warning{
+				{Line: 1, Path: "a", Message: "duplicate key, first defined on line 1; the last value is used"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.parser.Check([]byte(tt.data)))
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrMaxDepthExceeded = errors.New("maximum nesting depth exceeded")
+	ErrMaxNodesExceeded = errors.New("maximum node count exceeded")
+)
+
+// ctxCheckInterval is how many values are decoded between context checks.
+const ctxCheckInterval = 1024
+
+// maxNesting bounds the nesting depth of recursive-descent parsers when
+// ParseOptions.MaxDepth is not set, as encoding/json does, so that deeply
+// nested input fails instead of exhausting the stack.
+const maxNesting = 10000
+
+// limiter counts the values of a document while a ContextParser decodes it,
+// so that ParseOptions.MaxDepth, MaxNodes and cancellation stop hostile
+// input before it has been turned into a document.
+type limiter struct {
+	ctx      context.Context
+	maxDepth int
+	maxNodes int
+	nodes    int
+}
+
+// newLimiter returns nil when there is nothing to enforce.
+func newLimiter(ctx context.Context, opts ParseOptions) *limiter {
+	if opts.MaxDepth <= 0 && opts.MaxNodes <= 0 && ctx.Done() == nil {
+		return nil
+	}
+	return &limiter{ctx: ctx, maxDepth: opts.MaxDepth, maxNodes: opts.MaxNodes}
+}
+
+// value accounts for one object value or array element at depth, where
+// top-level keys are at depth 1.
+func (l *limiter) value(depth int) error {
+	if l == nil {
+		return nil
+	}
+
+	l.nodes++
+	if l.maxNodes > 0 && l.nodes > l.maxNodes {
+		return fmt.Errorf("%w: limit %d", ErrMaxNodesExceeded, l.maxNodes)
+	}
+	if l.maxDepth > 0 && depth > l.maxDepth {
+		return fmt.Errorf("%w: limit %d", ErrMaxDepthExceeded, l.maxDepth)
+	}
+	if l.nodes%ctxCheckInterval == 0 {
+		return l.ctx.Err()
+	}
+	return nil
+}
+
+// nested is value for parsers that recurse into nested values; it also
+// enforces maxNesting, and does so when l is nil.
+func (l *limiter) nested(depth int) error {
+	if depth > maxNesting {
+		return fmt.Errorf("%w: limit %d", ErrMaxDepthExceeded, maxNesting)
+	}
+	return l.value(depth)
+}
@@ -0,0 +1,308 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"code/diff"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported file format")
+	ErrReadFile          = errors.New("read file error")
+	ErrAbsPath           = errors.New("cannot resolve file path")
+	ErrInputTooLarge     = errors.New("input exceeds maximum size")
+)
+
+type Parser interface {
+	Parse(data []byte) (map[string]interface{}, error)
+}
+
+// PathParser is implemented by parsers whose input can refer to other files,
+// such as HOCON includes. FileParser.ParseFile calls ParsePath with the
+// absolute path of the file instead of Parse.
+type PathParser interface {
+	Parser
+	ParsePath(path string, data []byte) (map[string]interface{}, error)
+}
+
+// ContextParser is implemented by parsers that enforce ctx and the depth and
+// node limits of ParseOptions while decoding, so that hostile input fails
+// before the whole document is built. FileParser calls ParseContext instead
+// of Parse and ParsePath; path is the absolute path of the file, or empty.
+type ContextParser interface {
+	Parser
+	ParseContext(ctx context.Context, path string, data []byte, opts ParseOptions) (map[string]interface{}, error)
+}
+
+// Checker is implemented by parsers that can point out input they accept but
+// that is likely a mistake, such as duplicate keys. FileParser runs Check on
+// successfully parsed input when ParseOptions.Warn is set.
+type Checker interface {
+	Check(data []byte) []diff.Warning
+}
+
+// FileParser picks a Parser by file extension.
+type FileParser struct {
+	mu             sync.RWMutex
+	parsers        map[string]Parser
+	decompressors  map[string]Decompressor
+	allowedFormats []string
+	// compressedFormats lists the decompressor extensions in the order
+	// they were added, for error messages.
+	compressedFormats []string
+}
+
+func NewFileParser() *FileParser {
+	return &FileParser{
+		parsers:       make(map[string]Parser),
+		decompressors: make(map[string]Decompressor),
+	}
+}
+
+// Add registers p for the given extensions, replacing earlier registrations.
+func (r *FileParser) Add(p Parser, exts ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, ext := range exts {
+		ext = strings.ToLower(ext)
+		if _, exists := r.parsers[ext]; !exists {
+			r.allowedFormats = append(r.allowedFormats, ext)
+		}
+		r.parsers[ext] = p
+	}
+}
+
+// Extend registers every parser and decompressor of other into r.
+func (r *FileParser) Extend(other *FileParser) {
+	other.mu.RLock()
+	exts := append([]string(nil), other.allowedFormats...)
+	parsers := make(map[string]Parser, len(other.parsers))
+	for ext, p := range other.parsers {
+		parsers[ext] = p
+	}
+	compressed := append([]string(nil), other.compressedFormats...)
+	decompressors := make(map[string]Decompressor, len(other.decompressors))
+	for ext, d := range other.decompressors {
+		decompressors[ext] = d
+	}
+	other.mu.RUnlock()
+
+	for _, ext := range exts {
+		r.Add(parsers[ext], ext)
+	}
+	for _, ext := range compressed {
+		r.AddDecompressor(decompressors[ext], ext)
+	}
+}
+
+// ParseOptions controls how input is read before parsing.
+type ParseOptions struct {
+	// MaxSize limits the number of bytes read from the input; 0 means no limit.
+	MaxSize int64
+	// MaxDepth and MaxNodes limit the nesting depth and the number of values
+	// of the document for parsers implementing ContextParser; 0 means no
+	// limit. Top-level keys are at depth 1.
+	MaxDepth int
+	MaxNodes int
+	// Warn, when set, enables strict checks and receives their warnings.
+	Warn func(diff.Warning)
+
+	// archive and archiveDepth track the limits of archive members.
+	archive      *readBudget
+	archiveDepth int
+	// includes is set for files parsed through Includes.ParseFile.
+	includes *Includes
+}
+
+func (r *FileParser) Parse(path string) (map[string]interface{}, error) {
+	return r.ParseFile(context.Background(), path, ParseOptions{})
+}
+
+// ParseFile is Parse with cancellation and read limits.
+func (r *FileParser) ParseFile(ctx context.Context, path string, opts ParseOptions) (map[string]interface{}, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrAbsPath, path)
+	}
+	absPath = filepath.Clean(absPath)
+
+	f, err := os.Open(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrReadFile, absPath)
+	}
+	defer f.Close()
+
+	data, err := readAll(ctx, f, opts.MaxSize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, absPath)
+	}
+
+	return r.decode(ctx, absPath, absPath, data, opts)
+}
+
+// ParseReader parses everything read from rd with the parser registered for
+// format, given as an extension with or without the leading dot ("json", ".yml",
+// "json.gz").
+func (r *FileParser) ParseReader(ctx context.Context, rd io.Reader, format string, opts ParseOptions) (map[string]interface{}, error) {
+	data, err := readAll(ctx, rd, opts.MaxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if format != "" && !strings.HasPrefix(format, ".") {
+		format = "." + format
+	}
+
+	return r.decode(ctx, format, "", data, opts)
+}
+
+// decode decompresses and unpacks data as named by the extensions of name,
+// then parses it. path is the file the data was read from, or empty.
+func (r *FileParser) decode(ctx context.Context, name, path string, data []byte, opts ParseOptions) (map[string]interface{}, error) {
+	ext := strings.ToLower(filepath.Ext(name))
+	if d, ok := r.lookupDecompressor(ext); ok {
+		data, err := decompress(ctx, d, data, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, name)
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+		return r.decode(ctx, name, strings.TrimSuffix(path, filepath.Ext(path)), data, opts)
+	}
+
+	switch ext {
+	case ".tar", ".zip":
+		opts, err := enterArchive(opts)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, name)
+		}
+		if ext == ".tar" {
+			return r.parseTar(ctx, data, opts)
+		}
+		return r.parseZip(ctx, data, opts)
+	}
+	return r.parseData(ctx, path, ext, data, opts)
+}
+
+// parseData parses data with the parser registered for ext. path is the
+// file the data was read from, or empty for readers.
+func (r *FileParser) parseData(ctx context.Context, path, ext string, data []byte, opts ParseOptions) (map[string]interface{}, error) {
+	ext = strings.ToLower(ext)
+	parser, ok := r.lookup(ext)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s (allowed: %s)", ErrUnsupportedFormat, ext, r.getAllowedFormats())
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var result map[string]interface{}
+	var err error
+	if cp, ok := parser.(ContextParser); ok {
+		result, err = cp.ParseContext(ctx, path, data, opts)
+	} else if pp, ok := parser.(PathParser); ok && path != "" {
+		result, err = pp.ParsePath(path, data)
+	} else {
+		result, err = parser.Parse(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c, ok := parser.(Checker); ok && opts.Warn != nil {
+		for _, w := range c.Check(data) {
+			opts.Warn(w)
+		}
+	}
+	return result, nil
+}
+
+// readAll reads rd until EOF, failing once ctx is done or more than maxSize
+// bytes are available.
+func readAll(ctx context.Context, rd io.Reader, maxSize int64) ([]byte, error) {
+	rd = &contextReader{ctx: ctx, r: rd}
+	if maxSize > 0 {
+		rd = io.LimitReader(rd, maxSize+1)
+	}
+
+	data, err := io.ReadAll(rd)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("%w: %w", ErrReadFile, err)
+	}
+
+	if maxSize > 0 && int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w of %d bytes", ErrInputTooLarge, maxSize)
+	}
+
+	return data, nil
+}
+
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+func (r *FileParser) lookup(ext string) (Parser, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.parsers[ext]
+	return p, ok
+}
+
+func (r *FileParser) lookupDecompressor(ext string) (Decompressor, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	d, ok := r.decompressors[ext]
+	return d, ok
+}
+
+func (r *FileParser) getAllowedFormats() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	allowed := strings.Join(r.allowedFormats, ", ")
+	if len(r.compressedFormats) > 0 {
+		allowed += "; compressed: " + strings.Join(r.compressedFormats, ", ")
+	}
+	return allowed
+}
+
+var registered = NewFileParser()
+
+// RegisterParser makes p available for the given extensions in every
+// Differ created afterwards with the default parsers.
+func RegisterParser(p Parser, exts ...string) {
+	registered.Add(p, exts...)
+}
+
+// RegisterDecompressor makes d available for the given extensions in every
+// Differ created afterwards with the default parsers.
+func RegisterDecompressor(d Decompressor, exts ...string) {
+	registered.AddDecompressor(d, exts...)
+}
+
+// Registered returns the parsers added with RegisterParser and the
+// decompressors added with RegisterDecompressor.
+func Registered() *FileParser {
+	return registered
+}
@@ -0,0 +1,203 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code/diff"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type registryParseFileCase struct {
+	name         string
+	filePath     string
+	setupMock    func()
+	expectResult map[string]interface{}
+	expectErr    bool
+}
+
+func TestRegistry_ParseFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockParser := NewMockParser(ctrl)
+	reg := NewFileParser()
+	reg.Add(mockParser, ".mock")
+
+	tmpDir := t.TempDir()
+	content := []byte("dummy content")
+
+	tmpFile := filepath.Join(tmpDir, "file.mock")
+	require.NoError(t, os.WriteFile(tmpFile, content, 0644))
+
+	tmpFileUnsupported := filepath.Join(tmpDir, "file.mock.txt")
+	require.NoError(t, os.WriteFile(tmpFileUnsupported, content, 0644))
+
+	tests := []registryParseFileCase{
+		{
+			name:     "success",
+			filePath: tmpFile,
+			setupMock: func() {
+				mockParser.EXPECT().Parse(content).Return(map[string]interface{}{"key": "value"}, nil)
+			},
+			expectResult: map[string]interface{}{"key": "value"},
+		},
+		{
+			name:      "unsupported extension",
+			filePath:  tmpFileUnsupported,
+			setupMock: func() {},
+			expectErr: true,
+		},
+		{
+			name:     "parser error",
+			filePath: tmpFile,
+			setupMock: func() {
+				mockParser.EXPECT().Parse(content).Return(nil, errors.New("parse fail"))
+			},
+			expectErr: true,
+		},
+		{
+			name:      "file not found",
+			filePath:  filepath.Join(tmpDir, "nofile.mock"),
+			setupMock: func() {},
+			expectErr: true,
+		},
+		{
+			name:      "invalid path for Abs",
+			filePath:  string([]byte{0}), // invalid path
+			setupMock: func() {},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+			res, err := reg.Parse(tt.filePath)
+			if tt.expectErr {
+				require.Error(t, err)
+				require.Nil(t, res)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.expectResult, res)
+			}
+		})
+	}
+}
+
+func TestFileParser_AddReplacesExtension(t *testing.T) {
+	fp := NewFileParser()
+	fp.Add(&JSONParser{}, ".json", ".JSON")
+	fp.Add(&YAMLParser{}, ".json")
+
+	p, ok := fp.lookup(".json")
+	require.True(t, ok)
+	require.IsType(t, &YAMLParser{}, p)
+	require.Equal(t, ".json", fp.getAllowedFormats())
+}
+
+func TestFileParser_Extend(t *testing.T) {
+	base := NewFileParser()
+	base.Add(&JSONParser{}, ".json")
+
+	extra := NewFileParser()
+	extra.Add(&YAMLParser{}, ".yml", ".json")
+
+	base.Extend(extra)
+
+	p, ok := base.lookup(".json")
+	require.True(t, ok)
+	require.IsType(t, &YAMLParser{}, p)
+	require.Equal(t, ".json, .yml", base.getAllowedFormats())
+}
+
+func TestRegisterParser(t *testing.T) {
+	RegisterParser(&JSONParser{}, ".registered")
+
+	p, ok := Registered().lookup(".registered")
+	require.True(t, ok)
+	require.IsType(t, &JSONParser{}, p)
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestFileParser_ParseReader(t *testing.T) {
+	fp := NewFileParser()
+	fp.Add(&JSONParser{}, ".json")
+	fp.Add(&YAMLParser{}, ".yml")
+
+	tests := []struct {
+		name      string
+		reader    io.Reader
+		format    string
+		expected  map[string]interface{}
+		expectErr error
+	}{
+		{name: "format without dot", reader: strings.NewReader(`{"a": 1}`), format: "json", expected: map[string]interface{}{"a": 1.0}},
+		{name: "format with dot", reader: strings.NewReader("a: 1"), format: ".YML", expected: map[string]interface{}{"a": 1.0}},
+		{name: "unknown format", reader: strings.NewReader(""), format: "toml", expectErr: ErrUnsupportedFormat},
+		{name: "read error", reader: failingReader{}, format: "json", expectErr: ErrReadFile},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := fp.ParseReader(context.Background(), tt.reader, tt.format, ParseOptions{})
+			if tt.expectErr != nil {
+				require.ErrorIs(t, err, tt.expectErr)
+				require.Nil(t, res)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, res)
+		})
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	data, err := readAll(context.Background(), strings.NewReader("12345"), 5)
+	require.NoError(t, err)
+	require.Equal(t, []byte("12345"), data)
+
+	_, err = readAll(context.Background(), strings.NewReader("123456"), 5)
+	require.ErrorIs(t, err, ErrInputTooLarge)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = readAll(ctx, strings.NewReader("123"), 0)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFileParser_Warn(t *testing.T) {
+	fp := NewFileParser()
+	fp.Add(&JSONParser{}, ".json")
+	data := `{"a": 1, "a": 2}`
+
+	var warnings []diff.Warning
+	result, err := fp.ParseReader(context.Background(), strings.NewReader(data), "json", ParseOptions{
+		Warn: func(w diff.Warning) { warnings = append(warnings, w) },
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"a": 2.0}, result)
+	require.Equal(t, []diff.Warning{
+		{Line: 1, Path: "a", Message: "duplicate key, first defined on line 1; the last value is used"},
+	}, warnings)
+
+	_, err = fp.ParseReader(context.Background(), strings.NewReader(`{"a": 1, "a": `), "json", ParseOptions{
+		Warn: func(w diff.Warning) { t.Errorf("unexpected warning for invalid input: %s", w) },
+	})
+	require.Error(t, err)
+}
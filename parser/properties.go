@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PropertiesValueKey holds the value of a key that also has nested keys,
+// e.g. "appender.stdout" next to "appender.stdout.layout".
+const PropertiesValueKey = "_value"
+
+// PropertiesParser reads Java .properties files. Dotted keys are expanded
+// into nested objects unless Flat is set. Values are strings.
+type PropertiesParser struct {
+	Flat bool
+}
+
+func (p *PropertiesParser) Parse(data []byte) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		// Join continuation lines, dropping the leading whitespace of each.
+		for continues(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+		if continues(line) {
+			line = line[:len(line)-1]
+		}
+
+		rawKey, rawValue := splitProperty(line)
+		key, err := unescapeProperty(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("parse properties: line %d: %w", lineNo, err)
+		}
+		value, err := unescapeProperty(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("parse properties: line %d: %w", lineNo, err)
+		}
+
+		if p.Flat {
+			result[key] = value
+			continue
+		}
+		setProperty(result, strings.Split(key, "."), value)
+	}
+
+	return result, nil
+}
+
+// continues reports whether line ends with an odd number of backslashes.
+func continues(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// splitProperty splits a logical line at the first unescaped '=', ':' or
+// whitespace. Whitespace around the separator is not part of key or value.
+func splitProperty(line string) (string, string) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+
+	key := line[:end]
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	return key, rest
+}
+
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\u escape")
+			}
+			code, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\u escape: %w", err)
+			}
+			sb.WriteRune(rune(code))
+			i += 4
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String(), nil
+}
+
+// setProperty stores value under the dotted key. A key that is both a value
+// and a parent keeps its value under PropertiesValueKey.
+func setProperty(m map[string]interface{}, key []string, value string) {
+	for _, segment := range key[:len(key)-1] {
+		switch existing := m[segment].(type) {
+		case map[string]interface{}:
+			m = existing
+		case string:
+			child := map[string]interface{}{PropertiesValueKey: existing}
+			m[segment] = child
+			m = child
+		default:
+			child := make(map[string]interface{})
+			m[segment] = child
+			m = child
+		}
+	}
+
+	last := key[len(key)-1]
+	if child, ok := m[last].(map[string]interface{}); ok {
+		child[PropertiesValueKey] = value
+		return
+	}
+	m[last] = value
+}
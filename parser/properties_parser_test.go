@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPropertiesParser_Parse(t *testing.T) {
+	tests := []struct {
+		name      string
+		parser    *PropertiesParser
+		data      string
+		expected  map[string]interface{}
+		expectErr bool
+	}{
+		{
+			name:   "nested keys",
+			parser: &PropertiesParser{},
+			data: `# comment
+! also a comment
+server.port=8080
+server.host : localhost
+  name   shop
+empty=
+`,
+			expected: map[string]interface{}{
+				"server": map[string]interface{}{"port": "8080", "host": "localhost"},
+				"name":   "shop",
+				"empty":  "",
+			},
+		},
+		{
+			name:   "escapes and continuations",
+			parser: &PropertiesParser{},
+			data:   "path=C:\\\\dir\\\\file\r\ngreeting=hello \\\n    world\nkey\\ with\\=sep=v\\u00e9\\n\nliteral=\\#x\n",
+			expected: map[string]interface{}{
+				"path":         `C:\dir\file`,
+				"greeting":     "hello world",
+				"key with=sep": "vé\n",
+				"literal":      "#x",
+			},
+		},
+		{
+			name:   "value and children",
+			parser: &PropertiesParser{},
+			data:   "log4j.appender.stdout=ConsoleAppender\nlog4j.appender.stdout.layout=PatternLayout\nlog4j.root.level.name=INFO\nlog4j.root=x\n",
+			expected: map[string]interface{}{
+				"log4j": map[string]interface{}{
+					"appender": map[string]interface{}{
+						"stdout": map[string]interface{}{
+							PropertiesValueKey: "ConsoleAppender",
+							"layout":           "PatternLayout",
+						},
+					},
+					"root": map[string]interface{}{
+						PropertiesValueKey: "x",
+						"level":            map[string]interface{}{"name": "INFO"},
+					},
+				},
+			},
+		},
+		{
+			name:     "flat",
+			parser:   &PropertiesParser{Flat: true},
+			data:     "a.b=1\na=2\n",
+			expected: map[string]interface{}{"a.b": "1", "a": "2"},
+		},
+		{
+			name:     "trailing continuation",
+			parser:   &PropertiesParser{},
+			data:     "a=1\\",
+			expected: map[string]interface{}{"a": "1"},
+		},
+		{name: "bad unicode escape", parser: &PropertiesParser{}, data: "a=\\u12", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.parser.Parse([]byte(tt.data))
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Keys used for XML content that is not an element.
+const (
+	XMLAttrPrefix = "@"
+	XMLTextKey    = "#text"
+)
+
+// xmlNamespaceURI is the namespace bound to the reserved "xml" prefix.
+const xmlNamespaceURI = "http://www.w3.org/XML/1998/namespace"
+
+// XMLNamespaces selects how namespaced element and attribute names become keys.
+type XMLNamespaces int
+
+const (
+	// XMLNamespacesStrip drops namespaces and xmlns declarations: "project".
+	XMLNamespacesStrip XMLNamespaces = iota
+	// XMLNamespacesPrefix keeps the prefix declared in the document: "xsi:schemaLocation".
+	XMLNamespacesPrefix
+	// XMLNamespacesURI qualifies names with the namespace URI: "{http://maven.apache.org/POM/4.0.0}project".
+	XMLNamespacesURI
+)
+
+// XMLParser maps an XML document to nested maps: the root element becomes
+// the only top-level key, attributes become "@name" keys, repeated child
+// elements become arrays and text next to attributes or children is stored
+// under "#text". Elements with text only become strings. All values are
+// strings; comments and processing instructions are ignored, and external
+// entities are never resolved.
+//
+// Arrays lists element names, keyed as with Namespaces, that always become
+// arrays, so that a single <dependency> and two of them compare alike.
+// Elements or attributes of different namespaces that map to the same key
+// are an error.
+type XMLParser struct {
+	Namespaces XMLNamespaces
+	Arrays     []string
+}
+
+// xmlElement is an element being read.
+type xmlElement struct {
+	name     string
+	children map[string]interface{}
+	text     strings.Builder
+	// prefixes maps namespace URIs to the prefixes in scope at the element.
+	prefixes map[string]string
+	// names holds the XML name behind each key of children.
+	names map[string]xml.Name
+}
+
+func (p *XMLParser) Parse(data []byte) (map[string]interface{}, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	rootPrefixes := map[string]string{xmlNamespaceURI: "xml"}
+	arrays := make(map[string]bool, len(p.Arrays))
+	for _, name := range p.Arrays {
+		arrays[name] = true
+	}
+
+	var (
+		stack  []*xmlElement
+		result map[string]interface{}
+	)
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse xml: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if result != nil {
+				return nil, fmt.Errorf("parse xml: content after the root element")
+			}
+			prefixes := rootPrefixes
+			if len(stack) > 0 {
+				prefixes = stack[len(stack)-1].prefixes
+			}
+			prefixes = scopePrefixes(prefixes, t.Attr)
+
+			el := &xmlElement{
+				name:     p.name(t.Name, prefixes),
+				children: map[string]interface{}{},
+				prefixes: prefixes,
+				names:    map[string]xml.Name{},
+			}
+			for _, attr := range t.Attr {
+				if p.Namespaces == XMLNamespacesStrip && isNamespaceDecl(attr.Name) {
+					continue
+				}
+				key := XMLAttrPrefix + p.attrName(attr.Name, prefixes)
+				if err := el.claim(key, attr.Name); err != nil {
+					return nil, fmt.Errorf("parse xml: line %d: %w", xmlLine(dec), err)
+				}
+				el.children[key] = attr.Value
+			}
+			stack = append(stack, el)
+
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+
+		case xml.EndElement:
+			el := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			value := el.value()
+			if len(stack) == 0 {
+				result = map[string]interface{}{el.name: value}
+				continue
+			}
+			parent := stack[len(stack)-1]
+			if err := parent.claim(el.name, t.Name); err != nil {
+				return nil, fmt.Errorf("parse xml: line %d: %w", xmlLine(dec), err)
+			}
+			addChild(parent.children, el.name, value, arrays[el.name])
+		}
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("parse xml: no root element")
+	}
+	return result, nil
+}
+
+func (el *xmlElement) value() interface{} {
+	text := strings.TrimSpace(el.text.String())
+	if len(el.children) == 0 {
+		return text
+	}
+	if text != "" {
+		el.children[XMLTextKey] = text
+	}
+	return el.children
+}
+
+// claim records that key stands for name, failing when another name of the
+// element already maps to it.
+func (el *xmlElement) claim(key string, name xml.Name) error {
+	if other, ok := el.names[key]; ok && other != name {
+		return fmt.Errorf("%s and %s both map to key %q", xmlNameString(other), xmlNameString(name), key)
+	}
+	el.names[key] = name
+	return nil
+}
+
+// addChild stores a child element, turning repeated names, and names that
+// are always arrays, into arrays.
+func addChild(children map[string]interface{}, name string, value interface{}, array bool) {
+	existing, ok := children[name]
+	if !ok {
+		if array {
+			value = []interface{}{value}
+		}
+		children[name] = value
+		return
+	}
+	if list, ok := existing.([]interface{}); ok {
+		children[name] = append(list, value)
+		return
+	}
+	children[name] = []interface{}{existing, value}
+}
+
+// scopePrefixes returns the prefixes in scope inside an element with attrs,
+// copying parent only when the element declares prefixes of its own.
+func scopePrefixes(parent map[string]string, attrs []xml.Attr) map[string]string {
+	scoped, copied := parent, false
+	for _, attr := range attrs {
+		if attr.Name.Space != "xmlns" {
+			continue
+		}
+		if !copied {
+			scoped = make(map[string]string, len(parent)+1)
+			for uri, prefix := range parent {
+				scoped[uri] = prefix
+			}
+			copied = true
+		}
+		scoped[attr.Value] = attr.Name.Local
+	}
+	return scoped
+}
+
+func xmlLine(dec *xml.Decoder) int {
+	l, _ := dec.InputPos()
+	return l
+}
+
+func xmlNameString(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return "{" + name.Space + "}" + name.Local
+}
+
+func isNamespaceDecl(name xml.Name) bool {
+	return name.Space == "xmlns" || (name.Space == "" && name.Local == "xmlns")
+}
+
+func (p *XMLParser) name(name xml.Name, prefixes map[string]string) string {
+	if name.Space == "" {
+		return name.Local
+	}
+
+	switch p.Namespaces {
+	case XMLNamespacesPrefix:
+		if prefix := prefixes[name.Space]; prefix != "" {
+			return prefix + ":" + name.Local
+		}
+		return name.Local
+	case XMLNamespacesURI:
+		return "{" + name.Space + "}" + name.Local
+	default:
+		return name.Local
+	}
+}
+
+func (p *XMLParser) attrName(name xml.Name, prefixes map[string]string) string {
+	if name.Space == "xmlns" {
+		return "xmlns:" + name.Local
+	}
+	return p.name(name, prefixes)
+}
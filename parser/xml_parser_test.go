@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testPOM = `<?xml version="1.0" encoding="UTF-8"?>
+<!-- build file -->
+<project xmlns="http://maven.apache.org/POM/4.0.0"
+         xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+         xsi:schemaLocation="http://maven.apache.org/POM/4.0.0 pom.xsd">
+  <version>1.0</version>
+  <dependencies>
+    <dependency scope="test"><artifactId>junit</artifactId></dependency>
+    <dependency><artifactId>guava</artifactId></dependency>
+  </dependencies>
+  <name lang="en">Shop <![CDATA[& more]]></name>
+  <skip/>
+</project>`
+
+func TestXMLParser_Parse(t *testing.T) {
+	tests := []struct {
+		name      string
+		parser    *XMLParser
+		data      string
+		expected  map[string]interface{}
+		expectErr bool
+	}{
+		{
+			name:   "strip namespaces",
+			parser: &XMLParser{},
+			data:   testPOM,
+			expected: map[string]interface{}{
+				"project": map[string]interface{}{
+					"@schemaLocation": "http://maven.apache.org/POM/4.0.0 pom.xsd",
+					"version":         "1.0",
+					"dependencies": map[string]interface{}{
+						"dependency": []interface{}{
+							map[string]interface{}{"@scope": "test", "artifactId": "junit"},
+							map[string]interface{}{"artifactId": "guava"},
+						},
+					},
+					"name": map[string]interface{}{"@lang": "en", "#text": "Shop & more"},
+					"skip": "",
+				},
+			},
+		},
+		{
+			name:   "prefixes",
+			parser: &XMLParser{Namespaces: XMLNamespacesPrefix},
+			data:   `<c:root xmlns:c="urn:c" xmlns:d="urn:d" xml:lang="en" d:id="1"><d:item>x</d:item></c:root>`,
+			expected: map[string]interface{}{
+				"c:root": map[string]interface{}{
+					"@xmlns:c":  "urn:c",
+					"@xmlns:d":  "urn:d",
+					"@xml:lang": "en",
+					"@d:id":     "1",
+					"d:item":    "x",
+				},
+			},
+		},
+		{
+			name:   "uris",
+			parser: &XMLParser{Namespaces: XMLNamespacesURI},
+			data:   `<root xmlns="urn:a"><item>x</item></root>`,
+			expected: map[string]interface{}{
+				"{urn:a}root": map[string]interface{}{
+					"@xmlns":      "urn:a",
+					"{urn:a}item": "x",
+				},
+			},
+		},
+		{
+			name:   "prefixes scoped to their element",
+			parser: &XMLParser{Namespaces: XMLNamespacesPrefix},
+			data:   `<a:root xmlns:a="urn:x"><b:y xmlns:b="urn:x"><b:z/></b:y><a:w/></a:root>`,
+			expected: map[string]interface{}{
+				"a:root": map[string]interface{}{
+					"@xmlns:a": "urn:x",
+					"b:y":      map[string]interface{}{"@xmlns:b": "urn:x", "b:z": ""},
+					"a:w":      "",
+				},
+			},
+		},
+		{
+			name:   "always arrays",
+			parser: &XMLParser{Arrays: []string{"dependency"}},
+			data:   `<project><dependencies><dependency>junit</dependency></dependencies><dependency>x</dependency></project>`,
+			expected: map[string]interface{}{
+				"project": map[string]interface{}{
+					"dependencies": map[string]interface{}{"dependency": []interface{}{"junit"}},
+					"dependency":   []interface{}{"x"},
+				},
+			},
+		},
+		{
+			name:      "stripped element names collide",
+			parser:    &XMLParser{},
+			data:      `<root xmlns:a="urn:a" xmlns:b="urn:b"><a:item>1</a:item><b:item>2</b:item></root>`,
+			expectErr: true,
+		},
+		{
+			name:      "stripped attribute names collide",
+			parser:    &XMLParser{},
+			data:      `<root xmlns:a="urn:a" id="1" a:id="2"/>`,
+			expectErr: true,
+		},
+		{name: "malformed", parser: &XMLParser{}, data: `<a><b></a>`, expectErr: true},
+		{name: "empty", parser: &XMLParser{}, data: ``, expectErr: true},
+		{name: "two roots", parser: &XMLParser{}, data: `<a/><b/>`, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.parser.Parse([]byte(tt.data))
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"code/diff"
+	"code/internal/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxYAMLAliasNodes bounds the nodes expanded through aliases, so that a
+// small document cannot expand into an enormous one.
+const maxYAMLAliasNodes = 1_000_000
+
+// YAMLParser reads YAML. Aliases and << merge keys are expanded unless
+// KeepAnchors is set, in which case an alias is kept as "*name" and a merge
+// as a "<<" key, so that a change to an anchored value is reported once at
+// its definition rather than at every use. Tags maps custom tags such as
+// "!env" to resolvers; other custom tags are ignored.
+type YAMLParser struct {
+	KeepAnchors bool
+	Tags        map[string]YAMLTagResolver
+}
+
+// YAMLTag is a use of a custom tag, such as `!env HOME`.
+type YAMLTag struct {
+	Name  string
+	Value string
+	// File is the path of the document, or empty when it has none.
+	File string
+	// Include parses the file at path, relative to File, the way the
+	// current document is parsed. It fails for documents without a File and
+	// for paths outside the directory of the input.
+	Include func(path string) (interface{}, error)
+}
+
+// YAMLTagResolver returns the value of a custom tag.
+type YAMLTagResolver func(tag YAMLTag) (interface{}, error)
+
+// DefaultYAMLTags returns resolvers for !include, !env and !secret.
+func DefaultYAMLTags() map[string]YAMLTagResolver {
+	return map[string]YAMLTagResolver{
+		"!include": YAMLInclude,
+		"!env":     YAMLEnv,
+		"!secret":  YAMLSecret,
+	}
+}
+
+// YAMLInclude replaces `!include other.yml` with the parsed content of the file.
+func YAMLInclude(tag YAMLTag) (interface{}, error) {
+	return tag.Include(tag.Value)
+}
+
+// YAMLEnv replaces `!env NAME` with the value of the environment variable.
+func YAMLEnv(tag YAMLTag) (interface{}, error) {
+	value, ok := os.LookupEnv(tag.Value)
+	if !ok {
+		return nil, fmt.Errorf("environment variable %q is not set", tag.Value)
+	}
+	return value, nil
+}
+
+// YAMLSecret keeps `!secret name` as a reference, so that the diff shows
+// which secret is used but never its value.
+func YAMLSecret(tag YAMLTag) (interface{}, error) {
+	return tag.Name + " " + tag.Value, nil
+}
+
+func (p *YAMLParser) Parse(data []byte) (map[string]interface{}, error) {
+	return p.ParsePath("", data)
+}
+
+// ParsePath parses data read from path, which includes are relative to.
+func (p *YAMLParser) ParsePath(path string, data []byte) (map[string]interface{}, error) {
+	return p.ParseContext(context.Background(), path, data, ParseOptions{})
+}
+
+// ParseContext is ParsePath with the limits of opts enforced while nodes are
+// decoded, counting values expanded from aliases and includes. Includes are
+// read through NewIncludes and share the size limit of opts.
+func (p *YAMLParser) ParseContext(ctx context.Context, path string, data []byte, opts ParseOptions) (map[string]interface{}, error) {
+	d := &yamlDecoder{
+		parser:   p,
+		active:   make(map[*yaml.Node]bool),
+		limits:   newLimiter(ctx, opts),
+		includes: NewIncludes(ctx, path, opts),
+	}
+	value, err := d.decodeFile(path, data, 0)
+	if err != nil {
+		return nil, fmt.Errorf("parse yaml: %w", err)
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	result, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("parse yaml: document is %s, not a mapping", describeYAML(value))
+	}
+	return result, nil
+}
+
+// Check reports mapping keys that are not strings, such as 1 or true, which
+// are compared by their text; null keys, which are dropped; and mappings
+// that mix string and non-string keys.
+func (p *YAMLParser) Check(data []byte) []diff.Warning {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+
+	var warnings []diff.Warning
+	checkYAMLKeys(doc.Content[0], "", &warnings)
+	return warnings
+}
+
+func checkYAMLKeys(n *yaml.Node, path string, warnings *[]diff.Warning) {
+	switch n.Kind {
+	case yaml.SequenceNode:
+		for i, child := range n.Content {
+			checkYAMLKeys(child, fmt.Sprintf("%s[%d]", path, i), warnings)
+		}
+
+	case yaml.MappingNode:
+		var stringKeys, otherKeys int
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			keyPath := utils.JoinPath(path, key.Value)
+
+			switch {
+			case key.Kind != yaml.ScalarNode || key.Tag == "!!merge" || !strings.HasPrefix(key.Tag, "!!"):
+			case key.Tag == "!!str":
+				stringKeys++
+			case key.Tag == "!!null":
+				otherKeys++
+				*warnings = append(*warnings, diff.Warning{
+					Line:    key.Line,
+					Path:    path,
+					Message: fmt.Sprintf("null key %q is ignored", key.Value),
+				})
+			default:
+				otherKeys++
+				*warnings = append(*warnings, diff.Warning{
+					Line:    key.Line,
+					Path:    keyPath,
+					Message: fmt.Sprintf("%s key is compared as the string %q", key.Tag[2:], key.Value),
+				})
+			}
+
+			checkYAMLKeys(value, keyPath, warnings)
+		}
+
+		if stringKeys > 0 && otherKeys > 0 {
+			*warnings = append(*warnings, diff.Warning{
+				Line:    n.Line,
+				Path:    path,
+				Message: "mapping mixes string and non-string keys",
+			})
+		}
+	}
+}
+
+type yamlDecoder struct {
+	parser     *YAMLParser
+	file       string
+	depth      int
+	active     map[*yaml.Node]bool
+	aliasNodes int
+	limits     *limiter
+	includes   *Includes
+	// level is the nesting depth of the value being decoded.
+	level int
+}
+
+func (d *yamlDecoder) decodeFile(file string, data []byte, depth int) (interface{}, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	prevFile, prevDepth := d.file, d.depth
+	d.file, d.depth = file, depth
+	defer func() { d.file, d.depth = prevFile, prevDepth }()
+
+	return d.value(doc.Content[0], false)
+}
+
+// value decodes n; viaAlias is set while expanding an alias.
+func (d *yamlDecoder) value(n *yaml.Node, viaAlias bool) (interface{}, error) {
+	if viaAlias {
+		d.aliasNodes++
+		if d.aliasNodes > maxYAMLAliasNodes {
+			return nil, errors.New("aliases expand to too many nodes")
+		}
+	}
+
+	switch n.Kind {
+	case yaml.AliasNode:
+		if d.parser.KeepAnchors {
+			return "*" + n.Value, nil
+		}
+		if d.active[n.Alias] {
+			return nil, fmt.Errorf("line %d: alias *%s refers to itself", n.Line, n.Value)
+		}
+		d.active[n.Alias] = true
+		defer delete(d.active, n.Alias)
+		return d.value(n.Alias, true)
+
+	case yaml.SequenceNode:
+		items := make([]interface{}, 0, len(n.Content))
+		for _, child := range n.Content {
+			item, err := d.child(child, viaAlias)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+
+	case yaml.MappingNode:
+		return d.mapping(n, viaAlias)
+
+	case yaml.ScalarNode:
+		return d.scalar(n)
+	}
+	return nil, fmt.Errorf("line %d: unexpected node", n.Line)
+}
+
+// child decodes an element of a sequence or a value of a mapping one level
+// deeper than the current value.
+func (d *yamlDecoder) child(n *yaml.Node, viaAlias bool) (interface{}, error) {
+	d.level++
+	defer func() { d.level-- }()
+
+	if err := d.limits.value(d.level); err != nil {
+		return nil, err
+	}
+	return d.value(n, viaAlias)
+}
+
+func (d *yamlDecoder) mapping(n *yaml.Node, viaAlias bool) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(n.Content)/2)
+	lines := make(map[string]int, len(n.Content)/2)
+	var merges []*yaml.Node
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		keyNode, valueNode := n.Content[i], n.Content[i+1]
+		if keyNode.Kind == yaml.ScalarNode && keyNode.Tag == "!!merge" && !d.parser.KeepAnchors {
+			merges = append(merges, valueNode)
+			continue
+		}
+
+		key, ok, err := d.key(keyNode)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if line, exists := lines[key]; exists {
+			return nil, fmt.Errorf("line %d: mapping key %q already defined at line %d", keyNode.Line, key, line)
+		}
+		lines[key] = keyNode.Line
+
+		value, err := d.child(valueNode, viaAlias)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+
+	for _, merge := range merges {
+		if err := d.merge(result, merge, viaAlias); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// merge adds the keys of a << value missing from result. The value is a
+// mapping or a sequence of them, earlier ones taking precedence.
+func (d *yamlDecoder) merge(result map[string]interface{}, n *yaml.Node, viaAlias bool) error {
+	sources := []*yaml.Node{n}
+	if n.Kind == yaml.SequenceNode {
+		sources = n.Content
+	}
+
+	for _, source := range sources {
+		value, err := d.value(source, viaAlias)
+		if err != nil {
+			return err
+		}
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("line %d: merge value is %s, not a mapping", source.Line, describeYAML(value))
+		}
+		for k, v := range m {
+			if _, exists := result[k]; !exists {
+				result[k] = v
+			}
+		}
+	}
+	return nil
+}
+
+// key returns a mapping key as a string; other scalars keep their text.
+// ok is false for null keys, whose entries are dropped.
+func (d *yamlDecoder) key(n *yaml.Node) (key string, ok bool, err error) {
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind != yaml.ScalarNode {
+		return "", false, fmt.Errorf("line %d: mapping key must be a scalar", n.Line)
+	}
+	if n.Tag == "!!null" {
+		return "", false, nil
+	}
+	return n.Value, true, nil
+}
+
+func (d *yamlDecoder) scalar(n *yaml.Node) (interface{}, error) {
+	if strings.HasPrefix(n.Tag, "!") && !strings.HasPrefix(n.Tag, "!!") {
+		if resolve, ok := d.parser.Tags[n.Tag]; ok {
+			value, err := resolve(YAMLTag{Name: n.Tag, Value: n.Value, File: d.file, Include: d.include})
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s: %w", n.Line, n.Tag, err)
+			}
+			return value, nil
+		}
+	}
+
+	var value interface{}
+	if err := n.Decode(&value); err != nil {
+		return nil, err
+	}
+	switch v := value.(type) {
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	}
+	return value, nil
+}
+
+// include parses another file relative to the current one.
+func (d *yamlDecoder) include(name string) (interface{}, error) {
+	if d.depth >= maxIncludeDepth {
+		return nil, fmt.Errorf("includes nested deeper than %d levels", maxIncludeDepth)
+	}
+
+	path, data, err := d.includes.Load(d.file, name)
+	if err != nil {
+		return nil, err
+	}
+	value, err := d.decodeFile(path, data, d.depth+1)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return value, nil
+}
+
+func describeYAML(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case []interface{}:
+		return "a sequence"
+	case map[string]interface{}:
+		return "a mapping"
+	}
+	return "a scalar"
+}
@@ -0,0 +1,323 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code/diff"
+
+	"github.com/stretchr/testify/require"
+)
+
+type yamlParserCase struct {
+	name      string
+	data      []byte
+	expected  map[string]interface{}
+	expectErr bool
+}
+
+func TestYAMLParser_Parse(t *testing.T) {
+	parser := &YAMLParser{}
+
+	tests := []yamlParserCase{
+		{
+			name: "valid yaml with normalization",
+			data: []byte(`
+foo: 1
+bar:
+  baz: true
+nested:
+  value: 3
+list:
+  - 2
+  - name: item
+deep_list:
+  - - 4
+    - sublist:
+        value: 5
+`),
+			expected: map[string]interface{}{
+				"foo": float64(1),
+				"bar": map[string]interface{}{"baz": true},
+				"nested": map[string]interface{}{
+					"value": float64(3),
+				},
+				"list": []interface{}{
+					float64(2),
+					map[string]interface{}{"name": "item"},
+				},
+				"deep_list": []interface{}{
+					[]interface{}{
+						float64(4),
+						map[string]interface{}{
+							"sublist": map[string]interface{}{
+								"value": float64(5),
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "invalid yaml",
+			data:      []byte(":invalid"),
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parser.Parse(tt.data)
+			if tt.expectErr {
+				require.Error(t, err)
+				require.Nil(t, result)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestYAMLParser_Anchors(t *testing.T) {
+	data := []byte(`
+base: &base
+  image: golang:1.24
+  retry: 2
+extra: &extra
+  retry: 3
+  tags: [docker]
+build:
+  <<: *base
+  script: make build
+test:
+  <<: [*extra, *base]
+  retry: 1
+lint: *base
+`)
+
+	tests := []struct {
+		name     string
+		parser   *YAMLParser
+		expected map[string]interface{}
+	}{
+		{
+			name:   "expanded",
+			parser: &YAMLParser{},
+			expected: map[string]interface{}{
+				"base":  map[string]interface{}{"image": "golang:1.24", "retry": 2.0},
+				"extra": map[string]interface{}{"retry": 3.0, "tags": []interface{}{"docker"}},
+				"build": map[string]interface{}{"image": "golang:1.24", "retry": 2.0, "script": "make build"},
+				"test":  map[string]interface{}{"image": "golang:1.24", "retry": 1.0, "tags": []interface{}{"docker"}},
+				"lint":  map[string]interface{}{"image": "golang:1.24", "retry": 2.0},
+			},
+		},
+		{
+			name:   "kept",
+			parser: &YAMLParser{KeepAnchors: true},
+			expected: map[string]interface{}{
+				"base":  map[string]interface{}{"image": "golang:1.24", "retry": 2.0},
+				"extra": map[string]interface{}{"retry": 3.0, "tags": []interface{}{"docker"}},
+				"build": map[string]interface{}{"<<": "*base", "script": "make build"},
+				"test":  map[string]interface{}{"<<": []interface{}{"*extra", "*base"}, "retry": 1.0},
+				"lint":  "*base",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.parser.Parse(data)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestYAMLParser_Tags(t *testing.T) {
+	t.Setenv("GENDIFF_YAML_REGION", "eu-west-1")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db.yml"), []byte("host: !env GENDIFF_YAML_REGION\nport: 5432\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "loop.yml"), []byte("self: !include loop.yml\n"), 0o600))
+
+	tests := []struct {
+		name      string
+		data      string
+		tags      map[string]YAMLTagResolver
+		expected  map[string]interface{}
+		expectErr bool
+	}{
+		{
+			name: "default tags",
+			data: "region: !env GENDIFF_YAML_REGION\npassword: !secret db_password\ndb: !include db.yml\n",
+			tags: DefaultYAMLTags(),
+			expected: map[string]interface{}{
+				"region":   "eu-west-1",
+				"password": "!secret db_password",
+				"db":       map[string]interface{}{"host": "eu-west-1", "port": 5432.0},
+			},
+		},
+		{
+			name:     "unresolved tags keep their value",
+			data:     "region: !env GENDIFF_YAML_REGION\nport: !custom 80\n",
+			expected: map[string]interface{}{"region": "GENDIFF_YAML_REGION", "port": "80"},
+		},
+		{
+			name: "custom resolver",
+			data: "name: !upper web\n",
+			tags: map[string]YAMLTagResolver{
+				"!upper": func(tag YAMLTag) (interface{}, error) { return strings.ToUpper(tag.Value), nil },
+			},
+			expected: map[string]interface{}{"name": "WEB"},
+		},
+		{
+			name:      "unset variable",
+			data:      "region: !env GENDIFF_YAML_UNSET\n",
+			tags:      DefaultYAMLTags(),
+			expectErr: true,
+		},
+		{
+			name:      "missing include",
+			data:      "db: !include missing.yml\n",
+			tags:      DefaultYAMLTags(),
+			expectErr: true,
+		},
+		{
+			name:      "include cycle",
+			data:      "db: !include loop.yml\n",
+			tags:      DefaultYAMLTags(),
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p := &YAMLParser{Tags: tt.tags}
+			result, err := p.ParsePath(filepath.Join(dir, "main.yml"), []byte(tt.data))
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestYAMLParser_IncludeRestrictions(t *testing.T) {
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "secret.yml")
+	require.NoError(t, os.WriteFile(secret, []byte("password: hunter2\n"), 0o600))
+
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.yml")
+	for name, content := range map[string]string{
+		"a.yml": "a: " + strings.Repeat("x", 30) + "\n",
+		"b.yml": "b: " + strings.Repeat("x", 30) + "\n",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	p := &YAMLParser{Tags: DefaultYAMLTags()}
+
+	_, err := p.ParsePath(main, []byte("s: !include "+secret+"\n"))
+	require.ErrorIs(t, err, ErrIncludeOutsideDir)
+
+	_, err = p.ParsePath(main, []byte("s: !include ../"+filepath.Base(outside)+"/secret.yml\n"))
+	require.ErrorIs(t, err, ErrIncludeOutsideDir)
+
+	_, err = p.Parse([]byte("a: !include a.yml\n"))
+	require.ErrorIs(t, err, ErrIncludeWithoutPath)
+
+	// Each include fits the limit, but not both together.
+	both := []byte("a: !include a.yml\nb: !include b.yml\n")
+	_, err = p.ParseContext(context.Background(), main, both, ParseOptions{MaxSize: 70})
+	require.NoError(t, err)
+	_, err = p.ParseContext(context.Background(), main, both, ParseOptions{MaxSize: 50})
+	require.ErrorIs(t, err, ErrInputTooLarge)
+}
+
+func TestYAMLParser_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "duplicate key", data: "a: 1\na: 2\n"},
+		{name: "merge of a scalar", data: "a:\n  <<: 1\n"},
+		{name: "sequence document", data: "- a\n- b\n"},
+		{name: "alias expansion limit", data: billionLaughs()},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&YAMLParser{}).Parse([]byte(tt.data))
+			require.Error(t, err)
+		})
+	}
+}
+
+// billionLaughs returns a document whose aliases expand to 10^9 nodes.
+func billionLaughs() string {
+	var sb strings.Builder
+	sb.WriteString("a0: &a0 [x, x, x, x, x, x, x, x, x, x]\n")
+	for i := 1; i <= 9; i++ {
+		fmt.Fprintf(&sb, "a%d: &a%d [", i, i)
+		for j := 0; j < 10; j++ {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			fmt.Fprintf(&sb, "*a%d", i-1)
+		}
+		sb.WriteString("]\n")
+	}
+	return sb.String()
+}
+
+func TestYAMLParser_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []diff.Warning
+	}{
+		{
+			name: "string keys",
+			data: "a: 1\n\"2\": b\nlist:\n  - x: 1\n",
+		},
+		{
+			name: "non-string keys",
+			data: "codes:\n  200: ok\n  true: yes\nlist:\n  - 1.5: x\nnull: dropped\n",
+			expected: []diff.Warning{
+				{Line: 2, Path: "codes.200", Message: `int key is compared as the string "200"`},
+				{Line: 3, Path: "codes.true", Message: `bool key is compared as the string "true"`},
+				{Line: 5, Path: "list[0].1.5", Message: `float key is compared as the string "1.5"`},
+				{Line: 6, Path: "", Message: `null key "null" is ignored`},
+				{Line: 1, Path: "", Message: "mapping mixes string and non-string keys"},
+			},
+		},
+		{
+			name: "merge keys and aliases are not reported",
+			data: "base: &base\n  a: 1\nchild:\n  <<: *base\n  b: 2\n",
+		},
+		{
+			name: "invalid yaml",
+			data: ":\n  - [",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, (&YAMLParser{}).Check([]byte(tt.data)))
+		})
+	}
+}
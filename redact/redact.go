@@ -0,0 +1,182 @@
+// Package redact hides secret values in a diff tree while keeping changes
+// visible: each secret is replaced by a marker derived from its keyed hash,
+// so equal secrets get equal markers and different secrets different ones.
+package redact
+
+import (
+	"code/diff"
+	"code/internal/utils"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Rule selects values to redact. Key is matched case-insensitively against
+// the key name, e.g. "password" or "*_key"; a dotted Key is matched against
+// the whole path like compare.Rule patterns. Value matches the parts of
+// string values to redact, whatever their key.
+type Rule struct {
+	Key   string
+	Value *regexp.Regexp
+}
+
+var (
+	// PEM matches PEM encoded blocks such as certificates and private keys.
+	PEM = regexp.MustCompile(`-----BEGIN [A-Z0-9 ]+-----[\s\S]*?-----END [A-Z0-9 ]+-----`)
+	// JWT matches JSON Web Tokens.
+	JWT = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+)
+
+// Defaults returns the built-in rules: common secret key names, PEM blocks
+// and JWTs.
+func Defaults() []Rule {
+	rules := []Rule{{Value: PEM}, {Value: JWT}}
+	for _, key := range []string{
+		"password", "*_password", "passwd", "secret", "*_secret",
+		"token", "*_token", "*_key", "apikey", "credentials",
+	} {
+		rules = append(rules, Rule{Key: key})
+	}
+	return rules
+}
+
+// Redactor rewrites diff trees according to its rules.
+type Redactor struct {
+	keys    []string
+	values  []*regexp.Regexp
+	hmacKey []byte
+}
+
+// New returns a Redactor whose markers use a random key, so they can only be
+// compared within the output of one run and cannot be reversed by hashing
+// guesses of low-entropy secrets.
+func New(rules ...Rule) *Redactor {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("redact: read random key: %v", err))
+	}
+	return NewWithKey(key, rules...)
+}
+
+// NewWithKey returns a Redactor whose markers are HMAC-SHA256 values under
+// key, so that they stay comparable across runs that share the key. The key
+// must be kept as secret as the values it protects.
+func NewWithKey(key []byte, rules ...Rule) *Redactor {
+	r := &Redactor{hmacKey: append([]byte(nil), key...)}
+	for _, rule := range rules {
+		if rule.Key != "" {
+			pattern := strings.ToLower(rule.Key)
+			if !strings.Contains(pattern, ".") {
+				pattern = "**." + pattern
+			}
+			r.keys = append(r.keys, pattern)
+		}
+		if rule.Value != nil {
+			r.values = append(r.values, rule.Value)
+		}
+	}
+	return r
+}
+
+// Marker returns the replacement for a secret value.
+func (r *Redactor) Marker(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data = []byte(fmt.Sprint(v))
+	}
+	mac := hmac.New(sha256.New, r.hmacKey)
+	mac.Write(data)
+	return "[redacted:hmac:" + hex.EncodeToString(mac.Sum(nil)[:6]) + "]"
+}
+
+// Redact replaces secret values in nodes in place and returns them.
+func (r *Redactor) Redact(nodes []*diff.Node) []*diff.Node {
+	r.redactNodes(nodes, "", false)
+	return nodes
+}
+
+// Value redacts a single value found at the dotted keyPath.
+func (r *Redactor) Value(keyPath string, v interface{}) interface{} {
+	secret := false
+	parts := strings.Split(keyPath, ".")
+	for i := range parts {
+		if r.secretKey(strings.Join(parts[:i+1], ".")) {
+			secret = true
+			break
+		}
+	}
+	return r.redactValue(keyPath, v, secret)
+}
+
+func (r *Redactor) redactNodes(nodes []*diff.Node, parentPath string, secret bool) {
+	for _, node := range nodes {
+		keyPath := utils.JoinPath(parentPath, node.Key)
+		nodeSecret := secret || r.secretKey(keyPath)
+
+		oldValue, newValue := node.OldValue, node.NewValue
+		node.Value = r.redactValue(keyPath, node.Value, nodeSecret)
+		node.OldValue = r.redactValue(keyPath, oldValue, nodeSecret)
+		node.NewValue = r.redactValue(keyPath, newValue, nodeSecret)
+		if nodeSecret || redactedString(oldValue, node.OldValue) || redactedString(newValue, node.NewValue) {
+			// Notes such as "increased by 50%" describe the hidden values.
+			node.Annotation = ""
+		}
+
+		r.redactNodes(node.Children, keyPath, nodeSecret)
+	}
+}
+
+func (r *Redactor) redactValue(keyPath string, v interface{}, secret bool) interface{} {
+	if v == nil {
+		return nil
+	}
+	if secret {
+		return r.Marker(v)
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			childPath := utils.JoinPath(keyPath, k)
+			out[k] = r.redactValue(childPath, item, r.secretKey(childPath))
+		}
+		return out
+
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = r.redactValue(keyPath, item, false)
+		}
+		return out
+
+	case string:
+		for _, re := range r.values {
+			val = re.ReplaceAllStringFunc(val, func(s string) string { return r.Marker(s) })
+		}
+		return val
+
+	default:
+		return v
+	}
+}
+
+func redactedString(before, after interface{}) bool {
+	s, ok := before.(string)
+	return ok && s != after
+}
+
+func (r *Redactor) secretKey(keyPath string) bool {
+	keyPath = strings.ToLower(keyPath)
+	for _, pattern := range r.keys {
+		if utils.MatchPath(pattern, keyPath) {
+			return true
+		}
+	}
+	return false
+}
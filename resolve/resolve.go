@@ -0,0 +1,391 @@
+// Package resolve turns configuration templates into the effective
+// configuration before they are compared: it expands ${VAR} placeholders
+// and replaces {"$ref": ...} objects with the values they point to.
+package resolve
+
+import (
+	"bufio"
+	"bytes"
+	"code/internal/utils"
+	"code/parser"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnsetVariable = errors.New("variable is not set")
+	ErrInvalidEnv    = errors.New("invalid env file")
+	ErrRef           = errors.New("cannot resolve $ref")
+)
+
+// maxRefDepth bounds nested $ref resolution, which also stops ref cycles.
+const maxRefDepth = 32
+
+// maxRefNodes bounds the values copied from $ref targets when no node limit
+// is given, so that refs doubling each other cannot exhaust memory.
+const maxRefNodes = 1_000_000
+
+// ctxCheckInterval is how many values are visited between context checks.
+const ctxCheckInterval = 1024
+
+// nodeBudget counts the values visited while a document is resolved.
+type nodeBudget struct {
+	ctx      context.Context
+	maxNodes int
+	nodes    int
+}
+
+// add accounts for one object value or array element.
+func (b *nodeBudget) add() error {
+	b.nodes++
+	if b.maxNodes > 0 && b.nodes > b.maxNodes {
+		return fmt.Errorf("%w: limit %d", parser.ErrMaxNodesExceeded, b.maxNodes)
+	}
+	if b.nodes%ctxCheckInterval == 0 {
+		return b.ctx.Err()
+	}
+	return nil
+}
+
+// LookupFunc returns the value of a variable and whether it is set.
+// os.LookupEnv is one.
+type LookupFunc func(name string) (string, bool)
+
+// Vars looks names up in vars first and then in the process environment.
+func Vars(vars map[string]string) LookupFunc {
+	return func(name string) (string, bool) {
+		if value, ok := vars[name]; ok {
+			return value, true
+		}
+		return os.LookupEnv(name)
+	}
+}
+
+// LoadEnvFile reads variables from a .env file.
+func LoadEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read env file: %w", err)
+	}
+	vars, err := ParseEnvFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return vars, nil
+}
+
+// ParseEnvFile reads NAME=value lines. Blank lines, # comments and an
+// "export " prefix are ignored; values may be single- or double-quoted,
+// and double-quoted ones may contain \n, \t, \" and \\ escapes.
+func ParseEnvFile(data []byte) (map[string]string, error) {
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !isVarName(name) {
+			return nil, fmt.Errorf("%w: line %d: expected NAME=value", ErrInvalidEnv, lineNo)
+		}
+
+		value = strings.TrimSpace(value)
+		switch {
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidEnv, lineNo, err)
+			}
+			value = unquoted
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		vars[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEnv, err)
+	}
+	return vars, nil
+}
+
+func isVarName(name string) bool {
+	return placeholderName.MatchString(name)
+}
+
+var (
+	placeholderName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	placeholder     = regexp.MustCompile(`\$(\$?)\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+)
+
+// Expand replaces ${VAR} and ${VAR:-default} placeholders in the string
+// values of doc, in place. $${VAR} is kept as the literal ${VAR}. A
+// placeholder without a default whose variable is not set is an error.
+// Expansion stops once ctx is done or more than maxNodes values have been
+// visited; 0 means no limit.
+func Expand(ctx context.Context, doc map[string]interface{}, lookup LookupFunc, maxNodes int) error {
+	budget := &nodeBudget{ctx: ctx, maxNodes: maxNodes}
+	_, err := expandValue(doc, "", lookup, budget)
+	return err
+}
+
+func expandValue(v interface{}, path string, lookup LookupFunc, budget *nodeBudget) (interface{}, error) {
+	switch val := v.(type) {
+	case string:
+		return expandString(val, path, lookup)
+	case map[string]interface{}:
+		for key, child := range val {
+			if err := budget.add(); err != nil {
+				return nil, err
+			}
+			expanded, err := expandValue(child, utils.JoinPath(path, key), lookup, budget)
+			if err != nil {
+				return nil, err
+			}
+			val[key] = expanded
+		}
+	case []interface{}:
+		for i, child := range val {
+			if err := budget.add(); err != nil {
+				return nil, err
+			}
+			expanded, err := expandValue(child, fmt.Sprintf("%s[%d]", path, i), lookup, budget)
+			if err != nil {
+				return nil, err
+			}
+			val[i] = expanded
+		}
+	}
+	return v, nil
+}
+
+func expandString(s, path string, lookup LookupFunc) (string, error) {
+	var err error
+	result := placeholder.ReplaceAllStringFunc(s, func(match string) string {
+		m := placeholder.FindStringSubmatch(match)
+		if m[1] != "" {
+			return match[1:]
+		}
+		if value, ok := lookup(m[2]); ok {
+			return value
+		}
+		if m[3] != "" {
+			return m[4]
+		}
+		if err == nil {
+			err = fmt.Errorf("%w: %s (in %s)", ErrUnsetVariable, m[2], path)
+		}
+		return match
+	})
+	return result, err
+}
+
+// Loader parses the file at path.
+type Loader func(path string) (map[string]interface{}, error)
+
+// Refs returns doc with every object of the form {"$ref": "file#/pointer"}
+// replaced by the value it points to. file is the path doc was read from;
+// referenced files are relative to the referring one and read with load.
+// Without a file, as for readers and values, only refs into the same
+// document are allowed. A ref starting with "#" points into the same
+// document and one without "#" to a whole file. Other keys next to $ref
+// are merged over the referenced object. Resolution stops once ctx is done
+// or the result would hold more than maxNodes values; 0 means no limit.
+func Refs(ctx context.Context, doc map[string]interface{}, file string, load Loader, maxNodes int) (map[string]interface{}, error) {
+	r := &refResolver{
+		load:   load,
+		docs:   make(map[string]map[string]interface{}),
+		active: make(map[string]bool),
+		budget: &nodeBudget{ctx: ctx, maxNodes: maxNodes},
+	}
+	if file != "" {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrRef, err)
+		}
+		file = abs
+		r.docs[file] = doc
+	}
+
+	resolved, err := r.value(doc, file, doc, 0)
+	if err != nil {
+		return nil, err
+	}
+	result, ok := resolved.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: document resolves to %T, not an object", ErrRef, resolved)
+	}
+	return result, nil
+}
+
+type refResolver struct {
+	load   Loader
+	docs   map[string]map[string]interface{}
+	active map[string]bool
+	budget *nodeBudget
+	// copied counts the values copied from ref targets.
+	copied int
+}
+
+// count accounts for one value of the result at ref depth depth.
+func (r *refResolver) count(depth int) error {
+	if depth > 0 {
+		r.copied++
+		if r.copied > maxRefNodes {
+			return fmt.Errorf("%w: refs expand to more than %d values", ErrRef, maxRefNodes)
+		}
+	}
+	return r.budget.add()
+}
+
+// value returns a copy of v with refs resolved. file and root are the
+// document v belongs to.
+func (r *refResolver) value(v interface{}, file string, root map[string]interface{}, depth int) (interface{}, error) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := val["$ref"].(string); ok {
+			return r.ref(ref, val, file, root, depth)
+		}
+		result := make(map[string]interface{}, len(val))
+		for key, child := range val {
+			if err := r.count(depth); err != nil {
+				return nil, err
+			}
+			resolved, err := r.value(child, file, root, depth)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = resolved
+		}
+		return result, nil
+
+	case []interface{}:
+		result := make([]interface{}, len(val))
+		for i, child := range val {
+			if err := r.count(depth); err != nil {
+				return nil, err
+			}
+			resolved, err := r.value(child, file, root, depth)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = resolved
+		}
+		return result, nil
+	}
+	return v, nil
+}
+
+func (r *refResolver) ref(ref string, obj map[string]interface{}, file string, root map[string]interface{}, depth int) (interface{}, error) {
+	if depth >= maxRefDepth {
+		return nil, fmt.Errorf("%w: %q: refs nested deeper than %d levels", ErrRef, ref, maxRefDepth)
+	}
+
+	target, pointer, _ := strings.Cut(ref, "#")
+	targetFile, targetRoot := file, root
+	if target != "" {
+		if file == "" {
+			return nil, fmt.Errorf("%w: %q: refs to files need a document read from a file", ErrRef, ref)
+		}
+		targetFile = target
+		if !filepath.IsAbs(targetFile) {
+			targetFile = filepath.Join(filepath.Dir(file), targetFile)
+		}
+		targetFile = filepath.Clean(targetFile)
+
+		doc, ok := r.docs[targetFile]
+		if !ok {
+			loaded, err := r.load(targetFile)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q: %w", ErrRef, ref, err)
+			}
+			doc = loaded
+			r.docs[targetFile] = doc
+		}
+		targetRoot = doc
+	}
+
+	id := targetFile + "#" + pointer
+	if r.active[id] {
+		return nil, fmt.Errorf("%w: %q refers to itself", ErrRef, ref)
+	}
+	r.active[id] = true
+	defer delete(r.active, id)
+
+	value, err := lookupPointer(targetRoot, pointer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %v", ErrRef, ref, err)
+	}
+	resolved, err := r.value(value, targetFile, targetRoot, depth+1)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(obj) == 1 {
+		return resolved, nil
+	}
+	base, ok := resolved.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: %q: keys next to $ref need an object, got %T", ErrRef, ref, resolved)
+	}
+	for key, child := range obj {
+		if key == "$ref" {
+			continue
+		}
+		if err := r.count(depth); err != nil {
+			return nil, err
+		}
+		sibling, err := r.value(child, file, root, depth)
+		if err != nil {
+			return nil, err
+		}
+		base[key] = sibling
+	}
+	return base, nil
+}
+
+// lookupPointer follows a JSON pointer such as "/servers/0/host". As in
+// RFC 6901, "" is the whole document and "/" the key "".
+func lookupPointer(root map[string]interface{}, pointer string) (interface{}, error) {
+	if pointer == "" {
+		return root, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("pointer %q must start with /", pointer)
+	}
+
+	var current interface{} = root
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch val := current.(type) {
+		case map[string]interface{}:
+			next, ok := val[token]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found", token)
+			}
+			current = next
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(val) {
+				return nil, fmt.Errorf("index %q out of range", token)
+			}
+			current = val[i]
+		default:
+			return nil, fmt.Errorf("cannot descend into %T at %q", current, token)
+		}
+	}
+	return current, nil
+}
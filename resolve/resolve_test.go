@@ -0,0 +1,246 @@
+package resolve
+
+import (
+	"code/parser"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		expected  map[string]string
+		expectErr bool
+	}{
+		{
+			name: "values",
+			data: "# comment\n\nHOST=db.local\nexport PORT = 5432\nNAME='a # b'\nMSG=\"line\\nnext\"\nURL=http://x # trailing\nEMPTY=\n",
+			expected: map[string]string{
+				"HOST":  "db.local",
+				"PORT":  "5432",
+				"NAME":  "a # b",
+				"MSG":   "line\nnext",
+				"URL":   "http://x",
+				"EMPTY": "",
+			},
+		},
+		{name: "missing equals", data: "HOST\n", expectErr: true},
+		{name: "invalid name", data: "1HOST=x\n", expectErr: true},
+		{name: "bad escape", data: "A=\"\\q\"\n", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseEnvFile([]byte(tt.data))
+			if tt.expectErr {
+				require.ErrorIs(t, err, ErrInvalidEnv)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestVars(t *testing.T) {
+	t.Setenv("RESOLVE_TEST_PROCESS", "process")
+	t.Setenv("RESOLVE_TEST_BOTH", "process")
+
+	lookup := Vars(map[string]string{"RESOLVE_TEST_BOTH": "file"})
+
+	value, ok := lookup("RESOLVE_TEST_BOTH")
+	require.True(t, ok)
+	require.Equal(t, "file", value)
+
+	value, ok = lookup("RESOLVE_TEST_PROCESS")
+	require.True(t, ok)
+	require.Equal(t, "process", value)
+
+	_, ok = lookup("RESOLVE_TEST_UNSET")
+	require.False(t, ok)
+}
+
+func TestExpand(t *testing.T) {
+	lookup := Vars(map[string]string{"HOST": "db.local", "PORT": "5432", "EMPTY": ""})
+
+	tests := []struct {
+		name      string
+		doc       map[string]interface{}
+		expected  map[string]interface{}
+		expectErr bool
+	}{
+		{
+			name: "placeholders",
+			doc: map[string]interface{}{
+				"url":    "postgres://${HOST}:${PORT}/app",
+				"pool":   "${POOL:-5}",
+				"empty":  "${EMPTY:-fallback}",
+				"hosts":  []interface{}{"${HOST}", 1.0},
+				"nested": map[string]interface{}{"literal": "$${HOST}", "plain": "$HOST"},
+			},
+			expected: map[string]interface{}{
+				"url":    "postgres://db.local:5432/app",
+				"pool":   "5",
+				"empty":  "",
+				"hosts":  []interface{}{"db.local", 1.0},
+				"nested": map[string]interface{}{"literal": "${HOST}", "plain": "$HOST"},
+			},
+		},
+		{
+			name:      "unset variable",
+			doc:       map[string]interface{}{"a": map[string]interface{}{"b": "${RESOLVE_TEST_UNSET}"}},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := Expand(context.Background(), tt.doc, lookup, 0)
+			if tt.expectErr {
+				require.ErrorIs(t, err, ErrUnsetVariable)
+				require.Contains(t, err.Error(), "a.b")
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tt.doc)
+		})
+	}
+}
+
+func TestRefs(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]map[string]interface{}{
+		filepath.Join(dir, "common", "db.json"): {
+			"host":  "db.local",
+			"creds": map[string]interface{}{"$ref": "creds.json"},
+		},
+		filepath.Join(dir, "common", "creds.json"): {"user": "app"},
+		filepath.Join(dir, "loop.json"):            {"self": map[string]interface{}{"$ref": "loop.json"}},
+	}
+	load := func(path string) (map[string]interface{}, error) {
+		doc, ok := files[path]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return doc, nil
+	}
+
+	tests := []struct {
+		name      string
+		doc       map[string]interface{}
+		expected  map[string]interface{}
+		expectErr bool
+	}{
+		{
+			name: "files, pointers and siblings",
+			doc: map[string]interface{}{
+				"defaults": map[string]interface{}{"ports": []interface{}{80.0, 443.0}},
+				"db":       map[string]interface{}{"$ref": "common/db.json"},
+				"host":     map[string]interface{}{"$ref": "common/db.json#/host"},
+				"port":     map[string]interface{}{"$ref": "#/defaults/ports/1"},
+				"replica":  map[string]interface{}{"$ref": "common/db.json", "host": "replica.local"},
+				"list":     []interface{}{map[string]interface{}{"$ref": "#/defaults"}},
+			},
+			expected: map[string]interface{}{
+				"defaults": map[string]interface{}{"ports": []interface{}{80.0, 443.0}},
+				"db":       map[string]interface{}{"host": "db.local", "creds": map[string]interface{}{"user": "app"}},
+				"host":     "db.local",
+				"port":     443.0,
+				"replica":  map[string]interface{}{"host": "replica.local", "creds": map[string]interface{}{"user": "app"}},
+				"list":     []interface{}{map[string]interface{}{"ports": []interface{}{80.0, 443.0}}},
+			},
+		},
+		{name: "missing file", doc: map[string]interface{}{"a": map[string]interface{}{"$ref": "missing.json"}}, expectErr: true},
+		{name: "missing key", doc: map[string]interface{}{"a": map[string]interface{}{"$ref": "#/nope"}}, expectErr: true},
+		{name: "cycle", doc: map[string]interface{}{"a": map[string]interface{}{"$ref": "loop.json"}}, expectErr: true},
+		{name: "self reference", doc: map[string]interface{}{"a": map[string]interface{}{"$ref": "#/a"}}, expectErr: true},
+		{name: "siblings need an object", doc: map[string]interface{}{"a": map[string]interface{}{"$ref": "common/db.json#/host", "x": 1.0}}, expectErr: true},
+		{name: "document is not an object", doc: map[string]interface{}{"$ref": "common/db.json#/host"}, expectErr: true},
+		{
+			name:     "empty key",
+			doc:      map[string]interface{}{"": "empty", "a": map[string]interface{}{"$ref": "#/"}},
+			expected: map[string]interface{}{"": "empty", "a": "empty"},
+		},
+		{name: "empty key missing", doc: map[string]interface{}{"a": map[string]interface{}{"$ref": "#/"}}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Refs(context.Background(), tt.doc, filepath.Join(dir, "main.json"), load, 0)
+			if tt.expectErr {
+				require.ErrorIs(t, err, ErrRef)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestRefs_WithoutFile(t *testing.T) {
+	load := func(path string) (map[string]interface{}, error) {
+		t.Fatalf("unexpected load of %s", path)
+		return nil, nil
+	}
+
+	result, err := Refs(context.Background(), map[string]interface{}{
+		"base": map[string]interface{}{"port": 80.0},
+		"web":  map[string]interface{}{"$ref": "#/base"},
+	}, "", load, 0)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"port": 80.0}, result["web"])
+
+	_, err = Refs(context.Background(), map[string]interface{}{"db": map[string]interface{}{"$ref": "/etc/db.json"}}, "", load, 0)
+	require.ErrorIs(t, err, ErrRef)
+}
+
+func TestRefs_Limits(t *testing.T) {
+	// Each level refers to the previous one twice, doubling its size.
+	doubling := func() map[string]interface{} {
+		doc := map[string]interface{}{"a0": map[string]interface{}{"x": 1.0}}
+		for i := 1; i <= 40; i++ {
+			ref := map[string]interface{}{"$ref": fmt.Sprintf("#/a%d", i-1)}
+			doc[fmt.Sprintf("a%d", i)] = map[string]interface{}{"l": ref, "r": ref}
+		}
+		return doc
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Refs(context.Background(), doubling(), "", nil, 1000)
+	require.ErrorIs(t, err, parser.ErrMaxNodesExceeded)
+
+	_, err = Refs(context.Background(), doubling(), "", nil, 0)
+	require.ErrorIs(t, err, ErrRef)
+	require.Contains(t, err.Error(), "more than")
+
+	_, err = Refs(cancelled, doubling(), "", nil, 0)
+	require.ErrorIs(t, err, context.Canceled)
+
+	doc := map[string]interface{}{"base": map[string]interface{}{"port": 80.0}, "web": map[string]interface{}{"$ref": "#/base"}}
+	result, err := Refs(context.Background(), doc, "", nil, 4)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"port": 80.0}, result["web"])
+}
+
+func TestExpand_Limits(t *testing.T) {
+	unset := func(string) (string, bool) { return "", false }
+	doc := map[string]interface{}{"a": []interface{}{"${X:-1}", "${X:-2}", "${X:-3}"}}
+	err := Expand(context.Background(), doc, unset, 3)
+	require.ErrorIs(t, err, parser.ErrMaxNodesExceeded)
+
+	require.NoError(t, Expand(context.Background(), doc, unset, 4))
+	require.Equal(t, []interface{}{"1", "2", "3"}, doc["a"])
+}
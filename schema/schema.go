@@ -0,0 +1,286 @@
+// Package schema implements the subset of JSON Schema needed to validate
+// configuration documents and fill in their defaults: type, enum, required,
+// properties, additionalProperties, items, default, minimum, maximum,
+// minLength, maxLength and pattern. Boolean schemas are supported too.
+// Schemas using other validation keywords, such as $ref or allOf, are
+// rejected rather than partly enforced; annotations such as title and
+// description are ignored.
+package schema
+
+import (
+	"code/diff"
+	"code/internal/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrInvalidSchema = errors.New("invalid schema")
+
+// unsupportedKeywords are validation keywords outside the subset; a schema
+// using them would accept documents it is meant to reject.
+var unsupportedKeywords = []string{
+	"$ref", "$dynamicRef", "$recursiveRef",
+	"allOf", "anyOf", "oneOf", "not", "if", "then", "else",
+	"const", "multipleOf", "exclusiveMinimum", "exclusiveMaximum",
+	"patternProperties", "propertyNames", "minProperties", "maxProperties",
+	"dependencies", "dependentRequired", "dependentSchemas", "unevaluatedProperties",
+	"prefixItems", "contains", "minContains", "maxContains",
+	"minItems", "maxItems", "uniqueItems", "unevaluatedItems",
+}
+
+// Schema is a parsed JSON Schema.
+type Schema struct {
+	Type                 Types              `json:"type,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+
+	// reject is set by the boolean schema false.
+	reject  bool
+	pattern *regexp.Regexp
+}
+
+// Types holds the allowed types; "type" may be a string or a list.
+type Types []string
+
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = Types{one}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings: %w", err)
+	}
+	*t = many
+	return nil
+}
+
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*s = Schema{reject: !b}
+		return nil
+	}
+
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return err
+	}
+	for _, keyword := range unsupportedKeywords {
+		if _, ok := keywords[keyword]; ok {
+			return fmt.Errorf("keyword %q is not supported", keyword)
+		}
+	}
+
+	// The alias drops this method to avoid infinite recursion.
+	type plain Schema
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	return nil
+}
+
+// Parse parses a JSON Schema document.
+func Parse(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	return &s, nil
+}
+
+// Load reads and parses a JSON Schema file.
+func Load(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read schema: %w", err)
+	}
+	return Parse(data)
+}
+
+// Validate checks doc against the schema and returns every violation found,
+// with Path set to the dotted path of the offending value. Messages never
+// quote the value itself, which may be a secret.
+func (s *Schema) Validate(doc interface{}) []diff.Warning {
+	var warnings []diff.Warning
+	s.validate("", doc, &warnings)
+	return warnings
+}
+
+func (s *Schema) validate(keyPath string, v interface{}, warnings *[]diff.Warning) {
+	report := func(format string, args ...interface{}) {
+		*warnings = append(*warnings, diff.Warning{Path: keyPath, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.reject {
+		report("value is not allowed")
+		return
+	}
+
+	if len(s.Type) > 0 && !s.hasType(v) {
+		report("expected %s, got %s", strings.Join(s.Type, " or "), diff.TypeOf(v))
+		return
+	}
+
+	if len(s.Enum) > 0 && !s.inEnum(v) {
+		report("value is not one of the allowed values")
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		s.validateObject(keyPath, val, warnings, report)
+
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range val {
+				s.Items.validate(fmt.Sprintf("%s[%d]", keyPath, i), item, warnings)
+			}
+		}
+
+	case float64:
+		if s.Minimum != nil && val < *s.Minimum {
+			report("value is less than minimum %s", formatValue(*s.Minimum))
+		}
+		if s.Maximum != nil && val > *s.Maximum {
+			report("value is greater than maximum %s", formatValue(*s.Maximum))
+		}
+
+	case string:
+		n := utf8.RuneCountInString(val)
+		if s.MinLength != nil && n < *s.MinLength {
+			report("length %d is less than minLength %d", n, *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			report("length %d is greater than maxLength %d", n, *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(val) {
+			report("value does not match pattern %q", s.Pattern)
+		}
+	}
+}
+
+func (s *Schema) validateObject(keyPath string, obj map[string]interface{}, warnings *[]diff.Warning, report func(string, ...interface{})) {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			report("missing required property %q", name)
+		}
+	}
+
+	for _, key := range utils.SortedKeys(obj) {
+		childPath := utils.JoinPath(keyPath, key)
+		if prop, ok := s.Properties[key]; ok {
+			prop.validate(childPath, obj[key], warnings)
+			continue
+		}
+		if s.AdditionalProperties != nil {
+			if s.AdditionalProperties.reject {
+				*warnings = append(*warnings, diff.Warning{Path: childPath, Message: "additional property is not allowed"})
+				continue
+			}
+			s.AdditionalProperties.validate(childPath, obj[key], warnings)
+		}
+	}
+}
+
+func (s *Schema) hasType(v interface{}) bool {
+	actual := diff.TypeOf(v)
+	for _, t := range s.Type {
+		if t == actual {
+			return true
+		}
+		if n, ok := v.(float64); ok && t == "integer" && n == math.Trunc(n) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schema) inEnum(v interface{}) bool {
+	for _, allowed := range s.Enum {
+		if reflect.DeepEqual(allowed, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyDefaults fills in missing object properties that have a default,
+// recursing into existing objects and array items. doc is modified in place.
+func (s *Schema) ApplyDefaults(doc map[string]interface{}) {
+	s.applyDefaults(doc)
+}
+
+func (s *Schema) applyDefaults(v interface{}) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, prop := range s.Properties {
+			if _, ok := val[key]; !ok && prop.Default != nil {
+				val[key] = copyValue(prop.Default)
+			}
+			if child, ok := val[key]; ok {
+				prop.applyDefaults(child)
+			}
+		}
+
+	case []interface{}:
+		if s.Items != nil {
+			for _, item := range val {
+				s.Items.applyDefaults(item)
+			}
+		}
+	}
+}
+
+// copyValue deep-copies a default so documents never share it.
+func copyValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[k] = copyValue(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = copyValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func formatValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
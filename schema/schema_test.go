@@ -0,0 +1,159 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code/diff"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+	"type": "object",
+	"required": ["name"],
+	"additionalProperties": false,
+	"properties": {
+		"name": {"type": "string", "minLength": 2, "pattern": "^[a-z]+$"},
+		"replicas": {"type": "integer", "minimum": 1, "maximum": 10, "default": 1},
+		"mode": {"enum": ["fast", "safe"], "default": "safe"},
+		"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+		"server": {
+			"type": "object",
+			"properties": {
+				"port": {"type": ["integer", "string"], "default": 8080},
+				"tls": {"type": "object", "default": {"enabled": false}}
+			}
+		},
+		"ports": {"type": "array", "items": {"type": "object", "properties": {"protocol": {"default": "tcp"}}}}
+	}
+}`
+
+func mustParse(t *testing.T, data string) *Schema {
+	t.Helper()
+	s, err := Parse([]byte(data))
+	require.NoError(t, err)
+	return s
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "not json", data: "{"},
+		{name: "bad type", data: `{"type": 1}`},
+		{name: "bad pattern", data: `{"pattern": "("}`},
+		{name: "unsupported keyword", data: `{"allOf": [{"type": "object"}]}`},
+		{name: "nested unsupported keyword", data: `{"properties": {"db": {"$ref": "#/$defs/db"}}}`},
+		{name: "unsupported keyword in items", data: `{"items": {"patternProperties": {"^x-": {}}}}`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			require.ErrorIs(t, err, ErrInvalidSchema)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(path, []byte(testSchema), 0o600))
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	require.Equal(t, Types{"object"}, s.Type)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
+
+func TestSchema_Validate(t *testing.T) {
+	s := mustParse(t, testSchema)
+
+	tests := []struct {
+		name     string
+		doc      interface{}
+		expected []diff.Warning
+	}{
+		{
+			name: "valid",
+			doc: map[string]interface{}{
+				"name":     "web",
+				"replicas": 3.0,
+				"labels":   map[string]interface{}{"team": "core"},
+				"server":   map[string]interface{}{"port": "http"},
+			},
+		},
+		{
+			name: "violations",
+			doc: map[string]interface{}{
+				"replicas": 2.5,
+				"mode":     "slow",
+				"labels":   map[string]interface{}{"team": 1.0},
+				"extra":    true,
+				"ports":    []interface{}{"tcp"},
+			},
+			expected: []diff.Warning{
+				{Message: `missing required property "name"`},
+				{Path: "extra", Message: "additional property is not allowed"},
+				{Path: "labels.team", Message: "expected string, got number"},
+				{Path: "mode", Message: "value is not one of the allowed values"},
+				{Path: "ports[0]", Message: "expected object, got string"},
+				{Path: "replicas", Message: "expected integer, got number"},
+			},
+		},
+		{
+			name: "ranges and strings",
+			doc:  map[string]interface{}{"name": "A", "replicas": 11.0},
+			expected: []diff.Warning{
+				{Path: "name", Message: "length 1 is less than minLength 2"},
+				{Path: "name", Message: `value does not match pattern "^[a-z]+$"`},
+				{Path: "replicas", Message: "value is greater than maximum 10"},
+			},
+		},
+		{
+			name:     "wrong root type",
+			doc:      []interface{}{},
+			expected: []diff.Warning{{Message: "expected object, got array"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, s.Validate(tt.doc))
+		})
+	}
+}
+
+func TestSchema_ApplyDefaults(t *testing.T) {
+	s := mustParse(t, testSchema)
+
+	doc := map[string]interface{}{
+		"name":   "web",
+		"mode":   "fast",
+		"server": map[string]interface{}{},
+		"ports":  []interface{}{map[string]interface{}{"port": 80.0}},
+	}
+	s.ApplyDefaults(doc)
+
+	require.Equal(t, map[string]interface{}{
+		"name":     "web",
+		"mode":     "fast",
+		"replicas": 1.0,
+		"server": map[string]interface{}{
+			"port": 8080.0,
+			"tls":  map[string]interface{}{"enabled": false},
+		},
+		"ports": []interface{}{map[string]interface{}{"port": 80.0, "protocol": "tcp"}},
+	}, doc)
+
+	other := map[string]interface{}{"server": map[string]interface{}{}}
+	s.ApplyDefaults(other)
+	other["server"].(map[string]interface{})["tls"].(map[string]interface{})["enabled"] = true
+	require.Equal(t, false, doc["server"].(map[string]interface{})["tls"].(map[string]interface{})["enabled"])
+}